@@ -113,6 +113,9 @@ var (
 	ClusterFilterFlagsHelp = clusterFlagHelp
 	// SourceFlagsHelp is the text (and config) for source flags
 	SourceFlagsHelp = repoFlagHelp + offsetFlagHelp + flavorFlagHelp + tagFlagHelp + revisionFlagHelp
+	// ManifestFlagsHelp is the text (and config) for flags selecting a
+	// manifest
+	ManifestFlagsHelp = repoFlagHelp + offsetFlagHelp + flavorFlagHelp
 	// RectifyFilterFlagsHelp is the text (and config) for rectification flags
 	RectifyFilterFlagsHelp = repoFlagHelp + offsetFlagHelp + flavorFlagHelp + clusterFlagHelp + allFlagHelp
 	// DeployFilterFlagsHelp is the text and config for deploy flags
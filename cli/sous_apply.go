@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"flag"
+	"os"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousApply is the injectable command object used for `sous apply`
+type SousApply struct {
+	State *sous.State
+	sous.Resolver
+	flags struct {
+		dryrun string
+	}
+}
+
+func init() { TopLevelCommands["apply"] = &SousApply{} }
+
+const sousApplyHelp = `
+make exactly the changes recorded by sous plan
+
+usage: sous apply plan.json
+
+The plan is read as YAML if its file name ends in .yaml or .yml, and as JSON
+otherwise.
+
+Before changing anything, sous apply checks that every deployment the plan
+touches is still running as it was when the plan was made. If any of them have
+changed, nothing is applied, and you should make a new plan.
+
+Note: by default this command will make changes to live Singularity clusters.
+`
+
+// Help returns the help string
+func (*SousApply) Help() string { return sousApplyHelp }
+
+// AddFlags adds flags for sous apply
+func (sa *SousApply) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&sa.flags.dryrun, "dry-run", "none",
+		"prevent apply from actually changing things - "+
+			"values are none,scheduler,registry,both")
+}
+
+// RegisterOn adds the dry run option to the psyringe.
+func (sa *SousApply) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunOption(sa.flags.dryrun))
+	psy.Add(graph.ConfiguredDeployTimeout)
+}
+
+// Execute fulfils the cmdr.Executor interface
+func (sa *SousApply) Execute(args []string) cmdr.Result {
+	if len(args) != 1 {
+		return UsageErrorf("sous apply takes exactly one plan file")
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	defer f.Close()
+
+	plan, err := sous.ReadPlan(f, planIsYAML(args[0]))
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	if plan.Empty() {
+		return Successf("Nothing to apply.")
+	}
+
+	if err := sa.Apply(plan, sa.State.Defs.Clusters); err != nil {
+		return EnsureErrorResult(err)
+	}
+	return Success()
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/opentable/sous/util/yaml"
+	"github.com/pkg/errors"
+)
+
+// SousDefs is the description of the `sous defs` command.
+type SousDefs struct{}
+
+// DefsSubcommands are the subcommands of `sous defs`.
+var DefsSubcommands = cmdr.Commands{}
+
+func init() { TopLevelCommands["defs"] = &SousDefs{} }
+
+const sousDefsHelp = `
+view and edit the definitions on the Sous server
+
+usage: sous defs <command>
+
+The definitions are the clusters, env vars and resources that manifests are
+checked against. They are read from and written to the Sous server named by
+the Server config value, which checks changes against its manifests.
+
+sous defs get prints the Etag of what it read in a comment, which sous defs
+set and sous defs delete send back, so that the server refuses a change if
+someone else changed the definitions since they were read.
+`
+
+// Help returns the help for `sous defs`.
+func (*SousDefs) Help() string { return sousDefsHelp }
+
+// Subcommands returns the subcommands of `sous defs`.
+func (*SousDefs) Subcommands() cmdr.Commands { return DefsSubcommands }
+
+// Execute reports that `sous defs` needs a subcommand.
+func (*SousDefs) Execute(args []string) cmdr.Result {
+	err := UsageErrorf("usage: sous defs command")
+	err.Tip = "try `sous defs help` for a list of commands"
+	return err
+}
+
+// defsServer returns the client for the Sous server that sous defs commands
+// read from and write to.
+func defsServer(sm *graph.StateManager) (*sous.HTTPStateManager, error) {
+	hsm, ok := sm.StateManager.(*sous.HTTPStateManager)
+	if !ok {
+		return nil, errors.New("no Sous server is configured: set one with `sous config Server <url>`")
+	}
+	return hsm, nil
+}
+
+// etagComment starts the comment that sous defs get prints the Etag in.
+const etagComment = "# etag: "
+
+// successYAMLWithEtag prints v as YAML, after a comment with the Etag it was
+// read with.
+func successYAMLWithEtag(v interface{}, etag string) cmdr.Result {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return InternalErrorf("unable to marshal YAML: %s", err)
+	}
+	return SuccessData(append([]byte(etagComment+etag+"\n"), b...))
+}
+
+// readEtag returns the Etag in the comment that sous defs get printed at the
+// start of b, or "" if there isn't one.
+func readEtag(b []byte) string {
+	line, err := bufio.NewReader(bytes.NewReader(b)).ReadString('\n')
+	if err != nil && line == "" {
+		return ""
+	}
+	if !strings.HasPrefix(line, etagComment) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(line, etagComment))
+}
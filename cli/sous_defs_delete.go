@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousDefsDelete is the description of the `sous defs delete` command.
+type SousDefsDelete struct {
+	StateManager *graph.StateManager
+	flags        struct {
+		cluster string
+		etag    string
+	}
+}
+
+func init() { DefsSubcommands["delete"] = &SousDefsDelete{} }
+
+const sousDefsDeleteHelp = `
+delete a cluster from the definitions on the Sous server
+
+usage: sous defs delete -cluster <name> -etag <etag>
+
+The etag is the one printed by sous defs get -cluster <name>; the cluster is
+only deleted if nobody changed it since. The server refuses to delete a
+cluster that any manifest still deploys to.
+`
+
+// Help returns the help for `sous defs delete`.
+func (*SousDefsDelete) Help() string { return sousDefsDeleteHelp }
+
+// AddFlags adds the flags for `sous defs delete`.
+func (sdd *SousDefsDelete) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&sdd.flags.cluster, "cluster", "", "the cluster to delete")
+	fs.StringVar(&sdd.flags.etag, "etag", "", "the Etag the cluster was read with")
+}
+
+// Execute defines the behavior of `sous defs delete`.
+func (sdd *SousDefsDelete) Execute(args []string) cmdr.Result {
+	if sdd.flags.cluster == "" {
+		return UsageErrorf("sous defs delete needs a -cluster")
+	}
+	if sdd.flags.etag == "" {
+		return UsageErrorf("sous defs delete needs the -etag printed by sous defs get -cluster %s", sdd.flags.cluster)
+	}
+	server, err := defsServer(sdd.StateManager)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	if err := server.DeleteCluster(sdd.flags.cluster, sdd.flags.etag); err != nil {
+		return EnsureErrorResult(err)
+	}
+	return Successf("deleted cluster %s", sdd.flags.cluster)
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousDefsGet is the description of the `sous defs get` command.
+type SousDefsGet struct {
+	StateManager *graph.StateManager
+	flags        struct {
+		cluster string
+	}
+}
+
+func init() { DefsSubcommands["get"] = &SousDefsGet{} }
+
+const sousDefsGetHelp = `
+print the definitions on the Sous server as YAML
+
+usage: sous defs get [-cluster <name>]
+
+With -cluster, only the named cluster's definition is printed. The output
+starts with a comment holding the Etag of the definitions, which sous defs set
+and sous defs delete need to change them.
+`
+
+// Help returns the help for `sous defs get`.
+func (*SousDefsGet) Help() string { return sousDefsGetHelp }
+
+// AddFlags adds the flags for `sous defs get`.
+func (sdg *SousDefsGet) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&sdg.flags.cluster, "cluster", "", "print only the named cluster")
+}
+
+// Execute defines the behavior of `sous defs get`.
+func (sdg *SousDefsGet) Execute(args []string) cmdr.Result {
+	server, err := defsServer(sdg.StateManager)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	if sdg.flags.cluster != "" {
+		c, etag, err := server.ReadCluster(sdg.flags.cluster)
+		if err != nil {
+			return EnsureErrorResult(err)
+		}
+		return successYAMLWithEtag(c, etag)
+	}
+	defs, etag, err := server.ReadDefs()
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	return successYAMLWithEtag(defs, etag)
+}
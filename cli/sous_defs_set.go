@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/opentable/sous/util/yaml"
+)
+
+// SousDefsSet is the description of the `sous defs set` command.
+type SousDefsSet struct {
+	StateManager *graph.StateManager
+	flags        struct {
+		cluster string
+		etag    string
+	}
+}
+
+func init() { DefsSubcommands["set"] = &SousDefsSet{} }
+
+const sousDefsSetHelp = `
+replace the definitions on the Sous server
+
+usage: sous defs set [-cluster <name>] [-etag <etag>] [<file>]
+
+The definitions are read as YAML from file, or from standard input, in the
+format printed by sous defs get. With -cluster, only the named cluster is
+added or replaced.
+
+The definitions are only replaced if nobody changed them since they were read
+with the Etag in the comment sous defs get printed, or given with -etag.
+Without an Etag, a cluster is only added if there isn't one of that name.
+
+The server refuses definitions which its manifests don't satisfy, for example
+because they leave out a cluster that a manifest deploys to.
+`
+
+// Help returns the help for `sous defs set`.
+func (*SousDefsSet) Help() string { return sousDefsSetHelp }
+
+// AddFlags adds the flags for `sous defs set`.
+func (sds *SousDefsSet) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&sds.flags.cluster, "cluster", "", "add or replace only the named cluster")
+	fs.StringVar(&sds.flags.etag, "etag", "", "the Etag the definitions were read with")
+}
+
+// Execute defines the behavior of `sous defs set`.
+func (sds *SousDefsSet) Execute(args []string) cmdr.Result {
+	if len(args) > 1 {
+		return UsageErrorf("sous defs set takes at most one file")
+	}
+	server, err := defsServer(sds.StateManager)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	var b []byte
+	if len(args) == 1 {
+		b, err = ioutil.ReadFile(args[0])
+	} else {
+		b, err = ioutil.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	etag := sds.flags.etag
+	if etag == "" {
+		etag = readEtag(b)
+	}
+
+	if sds.flags.cluster != "" {
+		c := &sous.Cluster{}
+		if err := yaml.Unmarshal(b, c); err != nil {
+			return UsageErrorf("unable to parse cluster: %s", err)
+		}
+		if err := server.WriteCluster(sds.flags.cluster, c, etag); err != nil {
+			return EnsureErrorResult(err)
+		}
+		return Successf("set cluster %s", sds.flags.cluster)
+	}
+	defs := sous.Defs{}
+	if err := yaml.Unmarshal(b, &defs); err != nil {
+		return UsageErrorf("unable to parse defs: %s", err)
+	}
+	if err := server.WriteDefs(defs, etag); err != nil {
+		return EnsureErrorResult(err)
+	}
+	return Successf("set defs")
+}
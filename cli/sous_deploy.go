@@ -2,8 +2,10 @@ package cli
 
 import (
 	"flag"
+	"time"
 
 	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
 	"github.com/opentable/sous/util/cmdr"
 )
 
@@ -15,10 +17,16 @@ type SousDeploy struct {
 	Update       SousUpdate
 	Rectify      SousRectify
 	rectifyFlags struct {
-		dryrun string
+		dryrun         string
+		deployTimeout  int
+		overrideFreeze bool
 	}
 }
 
+// defaultDeployTimeout is how many seconds sous deploy waits for each
+// Singularity deploy to finish, by default.
+const defaultDeployTimeout = 300
+
 func init() { TopLevelCommands["deploy"] = &SousDeploy{} }
 
 const sousDeployHelp = `
@@ -28,6 +36,11 @@ usage: sous deploy -cluster <name> -tag <semver> [-use-otpl-deploy|-ignore-otpl-
 
 sous deploy will deploy the version tag for this application in the named
 cluster.
+
+sous deploy waits for Singularity to report whether the deploy succeeded, and
+exits nonzero if it failed or did not finish within -deploy-timeout seconds.
+
+If the cluster is frozen, nothing is deployed unless -override-freeze is given.
 `
 
 // Help returns the help string for this command.
@@ -41,6 +54,11 @@ func (sd *SousDeploy) AddFlags(fs *flag.FlagSet) {
 	fs.StringVar(&sd.rectifyFlags.dryrun, "dry-run", "none",
 		"prevent rectify from actually changing things - "+
 			"values are none,scheduler,registry,both")
+	fs.IntVar(&sd.rectifyFlags.deployTimeout, "deploy-timeout", defaultDeployTimeout,
+		"seconds to wait for the deploy to succeed or fail - "+
+			"0 means don't wait")
+	fs.BoolVar(&sd.rectifyFlags.overrideFreeze, "override-freeze", false,
+		"deploy even if the cluster is frozen")
 }
 
 // RegisterOn adds the DeploymentConfig to the psyringe to configure the
@@ -48,6 +66,8 @@ func (sd *SousDeploy) AddFlags(fs *flag.FlagSet) {
 func (sd *SousDeploy) RegisterOn(psy Addable) {
 	psy.Add(&sd.DeployFilterFlags)
 	psy.Add(&sd.OTPLFlags)
+	psy.Add(graph.DryrunOption(sd.rectifyFlags.dryrun))
+	psy.Add(graph.DeployTimeout(time.Duration(sd.rectifyFlags.deployTimeout) * time.Second))
 }
 
 // Execute fulfills the cmdr.Executor interface.
@@ -63,6 +83,7 @@ func (sd *SousDeploy) Execute(args []string) cmdr.Result {
 
 	rect := &SousRectify{SourceFlags: sd.DeployFilterFlags}
 	rect.flags.dryrun = sd.rectifyFlags.dryrun
+	rect.flags.overrideFreeze = sd.rectifyFlags.overrideFreeze
 
 	return sd.CLI.Plumbing(rect, []string{})
 }
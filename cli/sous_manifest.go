@@ -0,0 +1,30 @@
+package cli
+
+import "github.com/opentable/sous/util/cmdr"
+
+// SousManifest is the description of the `sous manifest` command.
+type SousManifest struct{}
+
+// ManifestSubcommands are the subcommands of `sous manifest`.
+var ManifestSubcommands = cmdr.Commands{}
+
+func init() { TopLevelCommands["manifest"] = &SousManifest{} }
+
+const sousManifestHelp = `
+inquire about manifests
+
+usage: sous manifest <command>
+`
+
+// Help returns the help for `sous manifest`.
+func (*SousManifest) Help() string { return sousManifestHelp }
+
+// Subcommands returns the subcommands of `sous manifest`.
+func (*SousManifest) Subcommands() cmdr.Commands { return ManifestSubcommands }
+
+// Execute reports that `sous manifest` needs a subcommand.
+func (*SousManifest) Execute(args []string) cmdr.Result {
+	err := UsageErrorf("usage: sous manifest command")
+	err.Tip = "try `sous manifest help` for a list of commands"
+	return err
+}
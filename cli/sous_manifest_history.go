@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"flag"
+	"os"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+	"github.com/pkg/errors"
+)
+
+// SousManifestHistory is the description of the `sous manifest history`
+// command.
+type SousManifestHistory struct {
+	config.DeployFilterFlags
+	TargetManifestID graph.TargetManifestID
+	StateManager     *graph.StateManager
+}
+
+func init() { ManifestSubcommands["history"] = &SousManifestHistory{} }
+
+const sousManifestHistoryHelp = `
+list the changes made to a manifest
+
+usage: sous manifest history [-repo <repo>] [-offset <dir>] [-flavor <flavor>]
+
+Each commit to the state which changed the manifest is listed, most recent
+first, with its author and time, and what it changed. The history is asked of
+the Sous server, if one is configured, or read from the local state's git
+repository otherwise.
+`
+
+// Help returns the help for `sous manifest history`.
+func (*SousManifestHistory) Help() string { return sousManifestHistoryHelp }
+
+// AddFlags adds the flags for `sous manifest history`.
+func (smh *SousManifestHistory) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &smh.DeployFilterFlags, ManifestFlagsHelp)
+}
+
+// RegisterOn adds the flags to the graph, to select the manifest.
+func (smh *SousManifestHistory) RegisterOn(psy Addable) {
+	psy.Add(&smh.DeployFilterFlags)
+}
+
+// Execute defines the behavior of `sous manifest history`.
+func (smh *SousManifestHistory) Execute(args []string) cmdr.Result {
+	historian, ok := smh.StateManager.StateManager.(sous.ManifestHistorian)
+	if !ok {
+		return EnsureErrorResult(errors.New("the state has no history"))
+	}
+	mid := sous.ManifestID(smh.TargetManifestID)
+	changes, err := historian.ManifestHistory(mid)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	if len(changes) == 0 {
+		return EnsureErrorResult(errors.Errorf("no history for manifest %s", mid))
+	}
+	sous.DumpManifestHistory(os.Stdout, changes)
+	return Success()
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousPlan is the injectable command object used for `sous plan`
+type SousPlan struct {
+	State       *sous.State
+	GDM         graph.CurrentGDM
+	SourceFlags config.DeployFilterFlags
+	sous.Resolver
+	flags struct {
+		output string
+	}
+}
+
+func init() { TopLevelCommands["plan"] = &SousPlan{} }
+
+const sousPlanHelp = `
+record the changes that sous rectify would make, without making them
+
+usage: sous plan [-o plan.json]
+
+The plan lists the deployments that would be created, deleted and modified,
+along with the differences for each modification. It is written as JSON, or
+as YAML if the output file ends in .yaml or .yml. If no output file is given,
+the plan is written to stdout as JSON.
+
+Once it has been reviewed, the plan can be carried out with sous apply.
+
+The -repo, -offset, -flavor and -cluster predicates limit the plan in the same
+way as they limit sous rectify.
+`
+
+// Help returns the help string
+func (*SousPlan) Help() string { return sousPlanHelp }
+
+// AddFlags adds flags for sous plan
+func (sp *SousPlan) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sp.SourceFlags, RectifyFilterFlagsHelp)
+
+	fs.StringVar(&sp.flags.output, "o", "", "file to write the plan to")
+}
+
+// RegisterOn adds the source flags and dry run option to the psyringe.
+func (sp *SousPlan) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunOption("none"))
+	psy.Add(graph.ConfiguredDeployTimeout)
+	psy.Add(&sp.SourceFlags)
+}
+
+// Execute fulfils the cmdr.Executor interface
+func (sp *SousPlan) Execute(args []string) cmdr.Result {
+	plan, err := sp.Plan(sp.GDM.Clone(), sp.State.Defs.Clusters)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	var out io.Writer = os.Stdout
+	if sp.flags.output != "" {
+		f, err := os.Create(sp.flags.output)
+		if err != nil {
+			return EnsureErrorResult(err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := sous.WritePlan(out, plan, planIsYAML(sp.flags.output)); err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	if sp.flags.output == "" {
+		return Success()
+	}
+	return Successf("%d creates, %d deletes, %d modifies planned in %s",
+		len(plan.Creates), len(plan.Deletes), len(plan.Modifies), sp.flags.output)
+}
+
+func planIsYAML(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yaml" || ext == ".yml"
+}
@@ -34,6 +34,7 @@ func (*SousQueryAds) Help() string { return sousBuildHelp }
 
 func (*SousQueryAds) RegisterOn(psy Addable) {
 	psy.Add(graph.DryrunOption("none"))
+	psy.Add(graph.ConfiguredDeployTimeout)
 }
 
 // Execute defines the behavior of `sous query ads`
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"flag"
+	"os"
+
+	"github.com/opentable/sous/config"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousQueryDrift is the description of the `sous query drift` command
+type SousQueryDrift struct {
+	State       *sous.State
+	GDM         graph.CurrentGDM
+	SourceFlags config.DeployFilterFlags
+	sous.Resolver
+	flags struct {
+		json bool
+	}
+}
+
+// driftResult is returned when there is drift, so that sous exits nonzero.
+type driftResult struct {
+	cmdr.ErrorResult
+}
+
+// ExitCode is 1, so that drift can be told apart from failing to check.
+func (driftResult) ExitCode() int { return 1 }
+
+func init() { QuerySubcommands["drift"] = &SousQueryDrift{} }
+
+const sousQueryDriftHelp = `
+reports how the running deployments differ from the global deploy manifest
+
+usage: sous query drift [-json]
+
+Every deployment that sous rectify would create, delete or modify is listed,
+along with the differences for each modification. Changes that rectify would
+hold back, for example because a cluster is frozen, are listed too.
+
+sous query drift exits with status 1 if there is any drift, so that it can be
+run as a periodic check.
+
+The -repo, -offset, -flavor and -cluster predicates limit the check in the
+same way as they limit sous rectify.
+`
+
+// Help returns the help string
+func (*SousQueryDrift) Help() string { return sousQueryDriftHelp }
+
+// AddFlags adds flags for sous query drift
+func (sd *SousQueryDrift) AddFlags(fs *flag.FlagSet) {
+	MustAddFlags(fs, &sd.SourceFlags, RectifyFilterFlagsHelp)
+
+	fs.BoolVar(&sd.flags.json, "json", false, "write the drift as JSON")
+}
+
+// RegisterOn adds the source flags and dry run option to the psyringe.
+func (sd *SousQueryDrift) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunOption("none"))
+	psy.Add(graph.ConfiguredDeployTimeout)
+	psy.Add(&sd.SourceFlags)
+}
+
+// Execute defines the behavior of `sous query drift`
+func (sd *SousQueryDrift) Execute(args []string) cmdr.Result {
+	drift, err := sd.Drift(sd.GDM.Clone(), sd.State.Defs.Clusters)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+
+	if sd.flags.json {
+		if err := sous.WriteDrift(os.Stdout, drift); err != nil {
+			return EnsureErrorResult(err)
+		}
+	} else if !drift.Empty() {
+		sous.DumpDrift(os.Stdout, drift)
+	}
+
+	if drift.Empty() {
+		return Success()
+	}
+	return driftResult{cmdr.UnknownErrorf("%d deployments have drifted", len(drift.Diffs))}
+}
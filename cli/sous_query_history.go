@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"flag"
+	"os"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousQueryHistory is the description of the `sous query history` command
+type SousQueryHistory struct {
+	History sous.ResolveHistory
+	flags   struct {
+		limit int
+	}
+}
+
+func init() { QuerySubcommands["history"] = &SousQueryHistory{} }
+
+const sousQueryHistoryHelp = `
+Lists recent resolves
+
+Each resolve run by this Sous, whether by 'sous rectify', 'sous deploy' or a
+Sous server, is recorded along with the changes it made and the errors it ran
+into. This command lists those records, most recent first.
+`
+
+// Help prints the help
+func (*SousQueryHistory) Help() string { return sousQueryHistoryHelp }
+
+// AddFlags adds the flags for `sous query history`
+func (sqh *SousQueryHistory) AddFlags(fs *flag.FlagSet) {
+	fs.IntVar(&sqh.flags.limit, "n", 20, "the number of resolves to list")
+}
+
+// RegisterOn adds the DryrunOption to the graph
+func (*SousQueryHistory) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunOption("none"))
+}
+
+// Execute defines the behavior of `sous query history`
+func (sqh *SousQueryHistory) Execute(args []string) cmdr.Result {
+	recs, err := sqh.History.ResolveRecords(sqh.flags.limit)
+	if err != nil {
+		return EnsureErrorResult(err)
+	}
+	sous.DumpResolveRecords(os.Stdout, recs)
+	return Success()
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/cmdr"
+)
+
+// SousQueryTombstones is the description of the `sous query tombstones` command
+type SousQueryTombstones struct {
+	State *sous.State
+}
+
+func init() { QuerySubcommands["tombstones"] = &SousQueryTombstones{} }
+
+const sousQueryTombstonesHelp = `
+Lists deployments that are awaiting deletion
+
+Deployments that are running in a cluster, but no longer have a manifest, are
+deleted according to the cluster's DeletePolicy. Under the "grace" policy they
+are tombstoned first, and deleted once the grace period has passed. This
+command lists those tombstones and when each deployment will be deleted.
+`
+
+// Help prints the help
+func (*SousQueryTombstones) Help() string { return sousQueryTombstonesHelp }
+
+// RegisterOn adds the DryrunOption to the graph
+func (*SousQueryTombstones) RegisterOn(psy Addable) {
+	psy.Add(graph.DryrunOption("none"))
+}
+
+// Execute defines the behavior of `sous query tombstones`
+func (sqt *SousQueryTombstones) Execute(args []string) cmdr.Result {
+	sous.DumpTombstones(os.Stdout, sqt.State.Tombstones, sqt.State.Defs.Clusters)
+	return Success()
+}
@@ -20,11 +20,13 @@ type SousRectify struct {
 	GDM          graph.CurrentGDM
 	SourceFlags  config.DeployFilterFlags
 	Engine       sous.SourceHostChooser
+	User         graph.LocalUser
 	sous.Resolver
 	flags struct {
 		dryrun                string
 		repo, offset, cluster string
 		all                   bool
+		overrideFreeze        bool
 	}
 }
 
@@ -47,6 +49,10 @@ line, sous rectify requires the -all flag to consider the whole tree. This is
 almost certainly not what you want. Even if it is, you certainly want to trial
 your rectifies with -dry-run=scheduler first.
 
+Clusters may be frozen, in which case deployments there are neither created
+nor changed. -override-freeze makes the changes anyway, and records that you
+did so in the resolve history.
+
 Note: by default this command will query a live docker registry and make
 changes to live Singularity clusters.
 `
@@ -61,12 +67,15 @@ func (sr *SousRectify) AddFlags(fs *flag.FlagSet) {
 	fs.StringVar(&sr.flags.dryrun, "dry-run", "none",
 		"prevent rectify from actually changing things - "+
 			"values are none,scheduler,registry,both")
+	fs.BoolVar(&sr.flags.overrideFreeze, "override-freeze", false,
+		"make changes to frozen clusters anyway")
 }
 
 // RegisterOn adds the DeploymentConfig to the psyringe to configure the
 // labeller and registrar.
 func (sr *SousRectify) RegisterOn(psy Addable) {
 	psy.Add(graph.DryrunOption(sr.flags.dryrun))
+	psy.Add(graph.ConfiguredDeployTimeout)
 	psy.Add(&sr.SourceFlags)
 }
 
@@ -76,6 +85,10 @@ func (sr *SousRectify) Execute(args []string) cmdr.Result {
 		return EnsureErrorResult(fmt.Errorf("Cowardly refusing rectify with neither contraint nor `-all`! (see `sous help rectify`)"))
 	}
 
+	if sr.flags.overrideFreeze {
+		sr.Resolver.FreezeOverride = sr.User.Username
+	}
+
 	if err := sr.Resolve(sr.GDM.Clone(), sr.State.Defs.Clusters); err != nil {
 		return EnsureErrorResult(err)
 	}
@@ -38,7 +38,19 @@ func init() { TopLevelCommands["server"] = &SousServer{} }
 const sousServerHelp = `
 Runs the API server for Sous
 
-usage: sous server
+usage: sous server [-cluster <name>]
+
+The server resolves the global deploy manifest on a schedule. With -cluster,
+it only resolves the named cluster, which it is then said to own.
+
+GET /status reports on the latest resolve, whether one is running, and when
+the next will start. POST /resolve starts a resolve straight away.
+
+If AuthTokensFile is configured, callers may authenticate with a bearer token
+from that file. If ServerCert, ServerKey and ClientCAs are configured, the
+server serves HTTPS, and callers may authenticate with client certificates.
+Once callers authenticate, only a manifest's owners, and the configured
+Admins, may change or delete it.
 `
 
 // Help is part of the cmdr.Command interface(s).
@@ -60,6 +72,7 @@ func (ss *SousServer) AddFlags(fs *flag.FlagSet) {
 // labeller and registrar.
 func (ss *SousServer) RegisterOn(psy Addable) {
 	psy.Add(graph.DryrunOption(ss.flags.dryrun))
+	psy.Add(graph.ConfiguredDeployTimeout)
 	psy.Add(&ss.DeployFilterFlags)
 }
 
@@ -68,10 +81,10 @@ func (ss *SousServer) Execute(args []string) cmdr.Result {
 	if err := ensureGDMExists(ss.flags.gdmRepo, ss.Config.StateLocation, ss.Log.Info.Printf); err != nil {
 		return EnsureErrorResult(err)
 	}
-	ss.Log.Info.Println("Starting scheduled GDM resolution.")
-	ss.AutoResolver.Kickoff()
+	filter := ss.AutoResolver.Status().Filter
+	ss.Log.Info.Printf("Starting scheduled GDM resolution for %s.", filter.String())
 	ss.Log.Info.Printf("Sous Server v%s running at %s", ss.Sous.Version, ss.flags.laddr)
-	return EnsureErrorResult(server.RunServer(ss.Verbosity, ss.flags.laddr)) //always non-nil
+	return EnsureErrorResult(server.RunServer(ss.Verbosity, ss.flags.laddr, ss.AutoResolver, ss.Config.Config)) //always non-nil
 }
 
 func ensureGDMExists(repo, localPath string, log func(string, ...interface{})) error {
@@ -103,11 +103,9 @@ func updateState(s *sous.State, gdm graph.CurrentGDM, sid sous.SourceID, did sou
 
 	gdm.Set(did, deployment)
 
-	manifests, err := gdm.Manifests(s.Defs)
-	if err != nil {
+	if err := s.UpdateManifests(gdm.Deployments); err != nil {
 		return EnsureErrorResult(err)
 	}
-	s.Manifests = manifests
 	return nil
 }
 
@@ -7,6 +7,7 @@ import (
 	"path"
 
 	"github.com/opentable/sous/ext/docker"
+	"github.com/opentable/sous/ext/singularity"
 	"github.com/opentable/sous/util/firsterr"
 )
 
@@ -24,15 +25,46 @@ type (
 		// BuildStateDir is a directory where information about builds
 		// performed by this user on this machine are stored.
 		BuildStateDir string `env:"SOUS_BUILD_STATE_DIR"`
+		// ResolveHistory is a SQLite database where a record of each resolve
+		// is kept.
+		ResolveHistory string `env:"SOUS_RESOLVE_HISTORY"`
+		// SecretsDir is a directory of secrets, used to resolve secret
+		// references in the Env of deployments. Each secret://path/key is
+		// read from the file SecretsDir/path/key.
+		SecretsDir string `env:"SOUS_SECRETS_DIR"`
+		// AuthToken is the bearer token this client sends to Server.
+		AuthToken string `env:"SOUS_AUTH_TOKEN"`
+		// ClientCert and ClientKey are PEM files with the certificate and key
+		// this client presents to Server, if it asks for one.
+		ClientCert string `env:"SOUS_CLIENT_CERT"`
+		ClientKey  string `env:"SOUS_CLIENT_KEY"`
+		// AuthTokensFile is a file of the bearer tokens a server accepts. Each
+		// line is a token, then whitespace, then the name of whoever it
+		// identifies. Blank lines and lines starting with # are ignored.
+		AuthTokensFile string `env:"SOUS_AUTH_TOKENS_FILE"`
+		// ServerCert and ServerKey are PEM files with the certificate and key
+		// a server uses to serve HTTPS. Without them, it serves plain HTTP.
+		ServerCert string `env:"SOUS_SERVER_CERT"`
+		ServerKey  string `env:"SOUS_SERVER_KEY"`
+		// ClientCAs is a PEM file of CA certificates. If it is set, a server
+		// serving HTTPS accepts client certificates signed by them, and
+		// identifies callers by their certificates' common names.
+		ClientCAs string `env:"SOUS_CLIENT_CAS"`
+		// Admins is a comma-separated list of the names of callers a server
+		// lets change any manifest, whoever owns it.
+		Admins string `env:"SOUS_ADMINS"`
 		// Docker is the Docker configuration.
 		Docker docker.Config
+		// Singularity is the Singularity configuration.
+		Singularity singularity.Config
 	}
 )
 
 // DefaultConfig returns the default configuration.
 func DefaultConfig() Config {
 	return Config{
-		Docker: docker.DefaultConfig(),
+		Docker:      docker.DefaultConfig(),
+		Singularity: singularity.DefaultConfig(),
 	}
 }
 
@@ -48,11 +80,19 @@ func (c *Config) FillDefaults() error {
 		func(e *error) {
 			*e = EnsureDirExists(c.StateLocation)
 		},
+		func(e *error) {
+			if c.ResolveHistory == "" {
+				c.ResolveHistory, *e = c.defaultResolveHistory()
+			}
+		},
+		func(e *error) {
+			*e = EnsureDirExists(path.Dir(c.ResolveHistory))
+		},
 	)
 }
 
-// defaultStateLocation returns the default state location.
-func (*Config) defaultStateLocation() (string, error) {
+// dataDir returns the directory Sous keeps its data in.
+func dataDir() (string, error) {
 	dataRoot := os.Getenv("XDG_DATA_HOME")
 	if dataRoot == "" {
 		u, err := user.Current()
@@ -61,8 +101,19 @@ func (*Config) defaultStateLocation() (string, error) {
 		}
 		dataRoot = path.Join(u.HomeDir, ".local", "share")
 	}
-	stateLocation := path.Join(dataRoot, "sous", "state")
-	return stateLocation, nil
+	return path.Join(dataRoot, "sous"), nil
+}
+
+// defaultStateLocation returns the default state location.
+func (*Config) defaultStateLocation() (string, error) {
+	dir, err := dataDir()
+	return path.Join(dir, "state"), err
+}
+
+// defaultResolveHistory returns the default resolve history database.
+func (*Config) defaultResolveHistory() (string, error) {
+	dir, err := dataDir()
+	return path.Join(dir, "history.db"), err
 }
 
 // EnsureDirExists creates the named directory if it does not exist.
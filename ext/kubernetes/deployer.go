@@ -0,0 +1,207 @@
+package kubernetes
+
+import (
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+type (
+	deployer struct {
+		Client   kubeClient
+		Registry sous.Registry
+	}
+
+	// kubeClient abstracts the raw interactions with the Kubernetes API.
+	kubeClient interface {
+		// ListDeployments lists the Sous-managed Deployments on a cluster.
+		ListDeployments(cluster string) ([]*DeploymentObject, error)
+		// ListCronJobs lists the Sous-managed CronJobs on a cluster.
+		ListCronJobs(cluster string) ([]*CronJobObject, error)
+		// PutDeployment creates or replaces a Deployment.
+		PutDeployment(cluster string, d *DeploymentObject) error
+		// PutCronJob creates or replaces a CronJob.
+		PutCronJob(cluster string, cj *CronJobObject) error
+		// DeleteDeployment deletes a Deployment by name.
+		DeleteDeployment(cluster, name string) error
+		// DeleteCronJob deletes a CronJob by name.
+		DeleteCronJob(cluster, name string) error
+	}
+)
+
+// NewDeployer creates a new Kubernetes-based sous.Deployer.
+func NewDeployer(r sous.Registry, c kubeClient) sous.Deployer {
+	return &deployer{Client: c, Registry: r}
+}
+
+// RunningDeployments collects the Sous-managed Deployments and CronJobs from
+// the Kubernetes clusters and returns them as sous.Deployments.
+func (r *deployer) RunningDeployments(clusters sous.Clusters) (sous.Deployments, error) {
+	deps := sous.NewDeployments()
+	seen := make(map[string]struct{})
+	for _, cluster := range clusters {
+		url := cluster.BaseURL
+		if _, ok := seen[url]; ok {
+			continue
+		}
+		seen[url] = struct{}{}
+
+		ds, err := r.Client.ListDeployments(url)
+		if err != nil {
+			return deps, err
+		}
+		for _, obj := range ds {
+			dep, err := BuildDeploymentFromDeployment(r.Registry, clusters, obj)
+			if err := addBuilt(deps, &dep, err); err != nil {
+				return deps, err
+			}
+		}
+
+		cjs, err := r.Client.ListCronJobs(url)
+		if err != nil {
+			return deps, err
+		}
+		for _, obj := range cjs {
+			dep, err := BuildDeploymentFromCronJob(r.Registry, clusters, obj)
+			if err := addBuilt(deps, &dep, err); err != nil {
+				return deps, err
+			}
+		}
+	}
+	return deps, nil
+}
+
+func addBuilt(deps sous.Deployments, dep *sous.Deployment, err error) error {
+	if err != nil {
+		if isMalformed(err) {
+			Log.Debug.Print(err)
+			return nil
+		}
+		return errors.Wrap(err, "building deployment")
+	}
+	Log.Vomit.Printf("Collected deployment: %v", dep)
+	deps.Add(dep)
+	return nil
+}
+
+func rectifyRecover(d interface{}, f string, err *error) {
+	if r := recover(); r != nil {
+		sous.Log.Warn.Printf("Panic in %s with %# v", f, d)
+		sous.Log.Warn.Printf("  %v", r)
+		sous.Log.Warn.Print(string(debug.Stack()))
+		*err = errors.Errorf("Panicked")
+	}
+}
+
+func (r *deployer) ImageName(d *sous.Deployment) (string, error) {
+	a, err := r.Registry.GetArtifact(d.SourceID)
+	if err != nil {
+		return "", err
+	}
+	return a.Name, nil
+}
+
+func (r *deployer) RectifyCreates(cc <-chan *sous.Deployment, errs chan<- sous.RectificationError) {
+	for d := range cc {
+		if err := r.RectifySingleCreate(d); err != nil {
+			errs <- &sous.CreateError{Deployment: d, Err: err}
+		}
+	}
+}
+
+func (r *deployer) RectifySingleCreate(d *sous.Deployment) (err error) {
+	Log.Debug.Printf("Rectifying creation %q:  \n %# v", d.ID(), d)
+	defer rectifyRecover(d, "RectifySingleCreate", &err)
+	return r.put(d)
+}
+
+func (r *deployer) RectifyDeletes(dc <-chan *sous.Deployment, errs chan<- sous.RectificationError) {
+	for d := range dc {
+		if err := r.RectifySingleDelete(d); err != nil {
+			errs <- &sous.DeleteError{Deployment: d, Err: err}
+		}
+	}
+}
+
+func (r *deployer) RectifySingleDelete(d *sous.Deployment) (err error) {
+	Log.Debug.Printf("Rectifying deletion %q", d.ID())
+	defer rectifyRecover(d, "RectifySingleDelete", &err)
+	return r.del(d)
+}
+
+func (r *deployer) RectifyModifies(mc <-chan *sous.DeploymentPair, errs chan<- sous.RectificationError) {
+	for pair := range mc {
+		if err := r.RectifySingleModification(pair); err != nil {
+			errs <- &sous.ChangeError{Deployments: pair, Err: err}
+		}
+	}
+}
+
+func (r *deployer) RectifySingleModification(pair *sous.DeploymentPair) (err error) {
+	Log.Debug.Printf("Rectifying modified %q: \n  %# v \n    =>  \n  %# v", pair.ID(), pair.Prior, pair.Post)
+	defer rectifyRecover(pair, "RectifySingleModification", &err)
+
+	priorCron, err := isCronJob(pair.Prior.Kind)
+	if err != nil {
+		return err
+	}
+	postCron, err := isCronJob(pair.Post.Kind)
+	if err != nil {
+		return err
+	}
+	if priorCron != postCron {
+		// The kind of object has changed, so the old one has to go.
+		if err := r.del(pair.Prior); err != nil {
+			return err
+		}
+	}
+	return r.put(pair.Post)
+}
+
+func (r *deployer) put(d *sous.Deployment) error {
+	cron, err := isCronJob(d.Kind)
+	if err != nil {
+		return err
+	}
+	if err := refuseSecretRefs(d.Env); err != nil {
+		return err
+	}
+	name, err := r.ImageName(d)
+	if err != nil {
+		return err
+	}
+	if cron {
+		return r.Client.PutCronJob(d.Cluster.BaseURL, buildCronJobObject(name, d))
+	}
+	return r.Client.PutDeployment(d.Cluster.BaseURL, buildDeploymentObject(name, d))
+}
+
+func (r *deployer) del(d *sous.Deployment) error {
+	cron, err := isCronJob(d.Kind)
+	if err != nil {
+		return err
+	}
+	name := MakeObjectName(d.ID())
+	if cron {
+		return r.Client.DeleteCronJob(d.Cluster.BaseURL, name)
+	}
+	return r.Client.DeleteDeployment(d.Cluster.BaseURL, name)
+}
+
+// refuseSecretRefs returns an error if e refers to secrets: the Kubernetes
+// deployer can't resolve them, and pods mustn't get the references as values.
+func refuseSecretRefs(e sous.Env) error {
+	refs := e.SecretRefs()
+	if len(refs) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return errors.Errorf("secret references aren't supported on Kubernetes clusters: %s", strings.Join(names, ", "))
+}
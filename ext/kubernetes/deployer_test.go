@@ -0,0 +1,262 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/ext/docker"
+	"github.com/opentable/sous/lib"
+)
+
+// fakeAPIServer is just enough of the Kubernetes API to exercise KubeAgent.
+type fakeAPIServer struct {
+	sync.Mutex
+	objects map[string]map[string]json.RawMessage
+}
+
+func newFakeAPIServer() *fakeAPIServer {
+	return &fakeAPIServer{objects: map[string]map[string]json.RawMessage{
+		"deployments": {},
+		"cronjobs":    {},
+	}}
+}
+
+func (f *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	// e.g. /apis/apps/v1/namespaces/default/deployments/name
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 6 || parts[4] != DefaultNamespace {
+		http.NotFound(w, r)
+		return
+	}
+	coll, ok := f.objects[parts[5]]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(parts) == 6 {
+		switch r.Method {
+		case "GET":
+			items := []json.RawMessage{}
+			for _, o := range coll {
+				items = append(items, o)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+		case "POST":
+			var meta struct{ Metadata ObjectMeta }
+			raw := json.RawMessage{}
+			json.NewDecoder(r.Body).Decode(&raw)
+			json.Unmarshal(raw, &meta)
+			if _, exists := coll[meta.Metadata.Name]; exists {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			coll[meta.Metadata.Name] = raw
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	name := parts[6]
+	obj, exists := coll[name]
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case "GET":
+		w.Write(obj)
+	case "PUT":
+		raw := json.RawMessage{}
+		json.NewDecoder(r.Body).Decode(&raw)
+		coll[name] = raw
+	case "DELETE":
+		delete(coll, name)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// labelledRegistry answers ImageLabels with the labels for the source ID
+// the image was named for by DummyRegistry.GetArtifact.
+type labelledRegistry struct {
+	*sous.DummyRegistry
+	sids map[string]sous.SourceID
+}
+
+func (lr *labelledRegistry) GetArtifact(sid sous.SourceID) (*sous.BuildArtifact, error) {
+	lr.sids[sid.String()] = sid
+	return lr.DummyRegistry.GetArtifact(sid)
+}
+
+func (lr *labelledRegistry) ImageLabels(in string) (map[string]string, error) {
+	return docker.Labels(lr.sids[in]), nil
+}
+
+func setupDeployer(t *testing.T) (*httptest.Server, *fakeAPIServer, sous.Deployer, sous.Clusters) {
+	api := newFakeAPIServer()
+	srv := httptest.NewServer(api)
+	reg := &labelledRegistry{DummyRegistry: sous.NewDummyRegistry(), sids: map[string]sous.SourceID{}}
+	clusters := sous.Clusters{
+		"kube-one": &sous.Cluster{Name: "kube-one", Kind: "kubernetes", BaseURL: srv.URL},
+	}
+	return srv, api, NewDeployer(reg, NewKubeAgent()), clusters
+}
+
+func testDeployment(clusters sous.Clusters, kind sous.ManifestKind, version string) *sous.Deployment {
+	return &sous.Deployment{
+		SourceID:    sous.MustNewSourceID("github.com/opentable/example", "", version),
+		ClusterName: "kube-one",
+		Cluster:     clusters["kube-one"],
+		Kind:        kind,
+		Owners:      sous.NewOwnerSet("judson", "sam"),
+		DeployConfig: sous.DeployConfig{
+			NumInstances: 3,
+			Env:          sous.Env{"GREETING": "hello"},
+			Resources:    sous.Resources{"cpus": "0.25", "memory": "256", "ports": "2"},
+			Volumes: sous.Volumes{
+				&sous.Volume{Host: "/var/log", Container: "/logs", Mode: sous.ReadWrite},
+			},
+		},
+	}
+}
+
+func rectify(d sous.Deployer, creates, deletes []*sous.Deployment, mods []*sous.DeploymentPair) []sous.RectificationError {
+	cc := make(chan *sous.Deployment, len(creates))
+	dc := make(chan *sous.Deployment, len(deletes))
+	mc := make(chan *sous.DeploymentPair, len(mods))
+	for _, c := range creates {
+		cc <- c
+	}
+	for _, dd := range deletes {
+		dc <- dd
+	}
+	for _, m := range mods {
+		mc <- m
+	}
+	close(cc)
+	close(dc)
+	close(mc)
+
+	errs := make(chan sous.RectificationError, 10)
+	d.RectifyCreates(cc, errs)
+	d.RectifyDeletes(dc, errs)
+	d.RectifyModifies(mc, errs)
+	close(errs)
+
+	var es []sous.RectificationError
+	for e := range errs {
+		es = append(es, e)
+	}
+	return es
+}
+
+func TestMakeObjectName(t *testing.T) {
+	assert := assert.New(t)
+
+	did := sous.DeployID{
+		ManifestID: sous.ManifestID{
+			Source: sous.SourceLocation{Repo: "github.com/opentable/an-extremely-long-repository-name", Dir: "some/offset"},
+			Flavor: "tasty",
+		},
+		Cluster: "cluster-1",
+	}
+	name := MakeObjectName(did)
+	assert.Regexp(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`, name)
+	assert.True(len(name) <= 52)
+
+	did.Cluster = "cluster-2"
+	assert.NotEqual(name, MakeObjectName(did))
+}
+
+func TestCreateAndReadBack(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	srv, api, d, clusters := setupDeployer(t)
+	defer srv.Close()
+
+	svc := testDeployment(clusters, sous.ManifestKindService, "1.2.3")
+	svc.Command = "/bin/app"
+	svc.Args = []string{"-port", "9000"}
+	svc.HealthCheck = sous.HealthCheck{URIPath: "/health", TimeoutSeconds: 5}
+	job := testDeployment(clusters, sous.ManifestKindScheduled, "1.2.3")
+	job.Flavor = "nightly"
+	job.Schedule = "30 4 * * MON-FRI"
+	job.ScheduleTimeZone = "America/Los_Angeles"
+
+	for _, e := range rectify(d, []*sous.Deployment{svc, job}, nil, nil) {
+		t.Error(e)
+	}
+	assert.Len(api.objects["deployments"], 1)
+	assert.Len(api.objects["cronjobs"], 1)
+
+	ads, err := d.RunningDeployments(clusters)
+	require.NoError(err)
+	require.Equal(2, ads.Len())
+
+	for _, expected := range []*sous.Deployment{svc, job} {
+		actual, ok := ads.Get(expected.ID())
+		if assert.True(ok, "%q not found", expected.ID()) {
+			different, diffs := actual.Diff(expected)
+			assert.False(different, "%v", diffs)
+		}
+	}
+}
+
+func TestModifyAndDelete(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	srv, api, d, clusters := setupDeployer(t)
+	defer srv.Close()
+
+	prior := testDeployment(clusters, sous.ManifestKindService, "1.2.3")
+	require.Empty(rectify(d, []*sous.Deployment{prior}, nil, nil))
+
+	post := testDeployment(clusters, sous.ManifestKindService, "1.2.4")
+	post.NumInstances = 5
+	require.Empty(rectify(d, nil, nil, []*sous.DeploymentPair{{Prior: prior, Post: post}}))
+
+	ads, err := d.RunningDeployments(clusters)
+	require.NoError(err)
+	actual, ok := ads.Get(post.ID())
+	require.True(ok)
+	assert.Equal(5, actual.NumInstances)
+	assert.Equal("1.2.4", actual.SourceID.Version.String())
+
+	require.Empty(rectify(d, nil, []*sous.Deployment{post}, nil))
+	assert.Len(api.objects["deployments"], 0)
+}
+
+func TestUnsupportedKind(t *testing.T) {
+	srv, _, d, clusters := setupDeployer(t)
+	defer srv.Close()
+
+	errs := rectify(d, []*sous.Deployment{testDeployment(clusters, sous.ManifestKindOnce, "1.2.3")}, nil, nil)
+	assert.Len(t, errs, 1)
+}
+
+func TestSecretRefsRefused(t *testing.T) {
+	srv, api, d, clusters := setupDeployer(t)
+	defer srv.Close()
+
+	dep := testDeployment(clusters, sous.ManifestKindService, "1.2.3")
+	dep.Env["DB_PASSWORD"] = "secret://db/prod/password"
+	errs := rectify(d, []*sous.Deployment{dep}, nil, nil)
+	if assert.Len(t, errs, 1) {
+		assert.Contains(t, errs[0].Error(), "DB_PASSWORD")
+	}
+	assert.Len(t, api.objects["deployments"], 0)
+}
@@ -0,0 +1,236 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/opentable/sous/ext/docker"
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/firsterr"
+	"github.com/pkg/errors"
+)
+
+type (
+	deploymentBuilder struct {
+		clusters  sous.Clusters
+		Target    sous.Deployment
+		registry  sous.Registry
+		meta      ObjectMeta
+		pod       PodSpec
+		container Container
+		instances *int32
+		kind      sous.ManifestKind
+		schedule  string
+		timeZone  string
+	}
+
+	// malformedObject is returned when a Kubernetes object can't be turned
+	// back into a Deployment. Such objects are skipped.
+	malformedObject struct {
+		message string
+	}
+)
+
+func (mo malformedObject) Error() string {
+	return mo.message
+}
+
+func isMalformed(err error) bool {
+	_, yes := errors.Cause(err).(malformedObject)
+	return yes
+}
+
+// BuildDeploymentFromDeployment collects the data for a sous.Deployment from
+// a Kubernetes Deployment.
+func BuildDeploymentFromDeployment(reg sous.Registry, clusters sous.Clusters, obj *DeploymentObject) (sous.Deployment, error) {
+	db := deploymentBuilder{
+		registry:  reg,
+		clusters:  clusters,
+		meta:      obj.Metadata,
+		pod:       obj.Spec.Template.Spec,
+		instances: obj.Spec.Replicas,
+	}
+	return db.Target, db.completeConstruction()
+}
+
+// BuildDeploymentFromCronJob collects the data for a sous.Deployment from a
+// Kubernetes CronJob.
+func BuildDeploymentFromCronJob(reg sous.Registry, clusters sous.Clusters, obj *CronJobObject) (sous.Deployment, error) {
+	db := deploymentBuilder{
+		registry:  reg,
+		clusters:  clusters,
+		meta:      obj.Metadata,
+		pod:       obj.Spec.JobTemplate.Spec.Template.Spec,
+		instances: obj.Spec.JobTemplate.Spec.Parallelism,
+		schedule:  obj.Spec.Schedule,
+	}
+	if obj.Spec.TimeZone != nil {
+		db.timeZone = *obj.Spec.TimeZone
+	}
+	return db.Target, db.completeConstruction()
+}
+
+func (db *deploymentBuilder) completeConstruction() error {
+	return firsterr.Returned(
+		db.findContainer,
+		db.retrieveImageLabels,
+		db.assignClusterName,
+		db.unpackAnnotations,
+		db.unpackDeployConfig,
+	)
+}
+
+func (db *deploymentBuilder) findContainer() error {
+	for _, c := range db.pod.Containers {
+		if c.Name == containerName {
+			db.container = c
+			return nil
+		}
+	}
+	return malformedObject{fmt.Sprintf("object %q has no %q container", db.meta.Name, containerName)}
+}
+
+func (db *deploymentBuilder) retrieveImageLabels() error {
+	// !!! HTTP request
+	labels, err := db.registry.ImageLabels(db.container.Image)
+	if err != nil {
+		return malformedObject{err.Error()}
+	}
+	Log.Vomit.Print("Labels: ", labels)
+
+	db.Target.SourceID, err = docker.SourceIDFromLabels(labels)
+	if err != nil {
+		return errors.Wrapf(malformedObject{err.Error()}, "for object: %s", db.meta.Name)
+	}
+	return nil
+}
+
+func (db *deploymentBuilder) assignClusterName() error {
+	name, ok := db.meta.Annotations[clusterAnnotation]
+	if !ok {
+		return malformedObject{fmt.Sprintf("object %q has no cluster annotation", db.meta.Name)}
+	}
+	cluster, ok := db.clusters[name]
+	if !ok {
+		return malformedObject{fmt.Sprintf("object %q is for cluster %q, which isn't being considered", db.meta.Name, name)}
+	}
+	db.Target.ClusterName = name
+	db.Target.Cluster = cluster
+	return nil
+}
+
+func (db *deploymentBuilder) unpackAnnotations() error {
+	db.Target.Flavor = db.meta.Annotations[flavorAnnotation]
+	db.Target.Kind = sous.ManifestKind(db.meta.Annotations[kindAnnotation])
+	if _, err := isCronJob(db.Target.Kind); err != nil {
+		return malformedObject{err.Error()}
+	}
+
+	db.Target.Owners = make(sous.OwnerSet)
+	for _, o := range strings.Split(db.meta.Annotations[ownersAnnotation], ",") {
+		if o != "" {
+			db.Target.Owners.Add(o)
+		}
+	}
+
+	if hc, ok := db.meta.Annotations[healthCheckAnnotation]; ok {
+		if err := json.Unmarshal([]byte(hc), &db.Target.HealthCheck); err != nil {
+			return malformedObject{fmt.Sprintf("object %q health check: %v", db.meta.Name, err)}
+		}
+	}
+	return nil
+}
+
+func (db *deploymentBuilder) unpackDeployConfig() error {
+	if len(db.container.Command) > 0 {
+		db.Target.Command = db.container.Command[0]
+	}
+	db.Target.Args = db.container.Args
+
+	db.Target.Env = make(sous.Env)
+	for _, e := range db.container.Env {
+		db.Target.Env[e.Name] = e.Value
+	}
+
+	cpus, err := parseCPU(db.container.Resources.Requests["cpu"])
+	if err != nil {
+		return malformedObject{err.Error()}
+	}
+	mem, err := parseMemory(db.container.Resources.Requests["memory"])
+	if err != nil {
+		return malformedObject{err.Error()}
+	}
+	ports := db.meta.Annotations[portsAnnotation]
+	if ports == "" {
+		ports = "1"
+	}
+	db.Target.Resources = sous.Resources{
+		"cpus":   fmt.Sprintf("%f", cpus),
+		"memory": fmt.Sprintf("%f", mem),
+		"ports":  ports,
+	}
+
+	if db.instances != nil {
+		db.Target.NumInstances = int(*db.instances)
+	}
+	db.Target.Schedule = db.schedule
+	db.Target.ScheduleTimeZone = db.timeZone
+
+	hostPaths := make(map[string]string, len(db.pod.Volumes))
+	for _, v := range db.pod.Volumes {
+		if v.HostPath != nil {
+			hostPaths[v.Name] = v.HostPath.Path
+		}
+	}
+	for _, m := range db.container.VolumeMounts {
+		host, ok := hostPaths[m.Name]
+		if !ok {
+			continue
+		}
+		mode := sous.ReadWrite
+		if m.ReadOnly {
+			mode = sous.ReadOnly
+		}
+		db.Target.DeployConfig.Volumes = append(db.Target.DeployConfig.Volumes,
+			&sous.Volume{Host: host, Container: m.MountPath, Mode: mode})
+	}
+	return nil
+}
+
+// parseCPU parses a Kubernetes CPU quantity into a number of CPUs.
+func parseCPU(q string) (float64, error) {
+	if strings.HasSuffix(q, "m") {
+		m, err := strconv.ParseFloat(strings.TrimSuffix(q, "m"), 64)
+		return m / 1000, errors.Wrapf(err, "parsing cpu quantity %q", q)
+	}
+	c, err := strconv.ParseFloat(q, 64)
+	return c, errors.Wrapf(err, "parsing cpu quantity %q", q)
+}
+
+var memorySuffixes = []struct {
+	suffix string
+	mb     float64
+}{
+	{"Ki", 1.0 / 1024},
+	{"Mi", 1},
+	{"Gi", 1024},
+	{"Ti", 1024 * 1024},
+	{"K", 1000.0 / (1024 * 1024)},
+	{"M", 1000 * 1000.0 / (1024 * 1024)},
+	{"G", 1000 * 1000 * 1000.0 / (1024 * 1024)},
+}
+
+// parseMemory parses a Kubernetes memory quantity into megabytes, as used by
+// sous.Resources.
+func parseMemory(q string) (float64, error) {
+	for _, s := range memorySuffixes {
+		if strings.HasSuffix(q, s.suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSuffix(q, s.suffix), 64)
+			return n * s.mb, errors.Wrapf(err, "parsing memory quantity %q", q)
+		}
+	}
+	b, err := strconv.ParseFloat(q, 64)
+	return b / (1024 * 1024), errors.Wrapf(err, "parsing memory quantity %q", q)
+}
@@ -0,0 +1,39 @@
+package kubernetes
+
+import "log"
+
+// DryrunAgent reads from Kubernetes clusters like a KubeAgent, but only logs
+// the changes it would make.
+type DryrunAgent struct {
+	*KubeAgent
+	logger *log.Logger
+}
+
+// NewDryrunAgent returns a DryrunAgent that logs to l.
+func NewDryrunAgent(l *log.Logger) *DryrunAgent {
+	return &DryrunAgent{KubeAgent: NewKubeAgent(), logger: l}
+}
+
+// PutDeployment logs the Deployment that would be put.
+func (da *DryrunAgent) PutDeployment(cluster string, d *DeploymentObject) error {
+	da.logger.Printf("Putting deployment %s %s: %+v", cluster, d.Metadata.Name, d.Spec)
+	return nil
+}
+
+// PutCronJob logs the CronJob that would be put.
+func (da *DryrunAgent) PutCronJob(cluster string, cj *CronJobObject) error {
+	da.logger.Printf("Putting cronjob %s %s: %+v", cluster, cj.Metadata.Name, cj.Spec)
+	return nil
+}
+
+// DeleteDeployment logs the Deployment that would be deleted.
+func (da *DryrunAgent) DeleteDeployment(cluster, name string) error {
+	da.logger.Printf("Deleting deployment %s %s", cluster, name)
+	return nil
+}
+
+// DeleteCronJob logs the CronJob that would be deleted.
+func (da *DryrunAgent) DeleteCronJob(cluster, name string) error {
+	da.logger.Printf("Deleting cronjob %s %s", cluster, name)
+	return nil
+}
@@ -0,0 +1,126 @@
+package kubernetes
+
+// These are minimal renderings of the Kubernetes API objects that Sous reads
+// and writes. Only the fields Sous cares about are included; anything else
+// the API server returns is ignored.
+
+type (
+	// ObjectMeta is the metadata common to all Kubernetes objects.
+	ObjectMeta struct {
+		Name            string            `json:"name,omitempty"`
+		Namespace       string            `json:"namespace,omitempty"`
+		ResourceVersion string            `json:"resourceVersion,omitempty"`
+		Labels          map[string]string `json:"labels,omitempty"`
+		Annotations     map[string]string `json:"annotations,omitempty"`
+	}
+
+	// DeploymentObject is a Kubernetes apps/v1 Deployment.
+	DeploymentObject struct {
+		APIVersion string         `json:"apiVersion"`
+		Kind       string         `json:"kind"`
+		Metadata   ObjectMeta     `json:"metadata"`
+		Spec       DeploymentSpec `json:"spec"`
+	}
+
+	// DeploymentSpec is the spec of a DeploymentObject.
+	DeploymentSpec struct {
+		Replicas *int32          `json:"replicas,omitempty"`
+		Selector *LabelSelector  `json:"selector,omitempty"`
+		Template PodTemplateSpec `json:"template"`
+	}
+
+	// CronJobObject is a Kubernetes batch/v1 CronJob.
+	CronJobObject struct {
+		APIVersion string      `json:"apiVersion"`
+		Kind       string      `json:"kind"`
+		Metadata   ObjectMeta  `json:"metadata"`
+		Spec       CronJobSpec `json:"spec"`
+	}
+
+	// CronJobSpec is the spec of a CronJobObject.
+	CronJobSpec struct {
+		Schedule    string          `json:"schedule"`
+		TimeZone    *string         `json:"timeZone,omitempty"`
+		Suspend     *bool           `json:"suspend,omitempty"`
+		JobTemplate JobTemplateSpec `json:"jobTemplate"`
+	}
+
+	// JobTemplateSpec describes the Jobs a CronJob creates.
+	JobTemplateSpec struct {
+		Metadata ObjectMeta `json:"metadata,omitempty"`
+		Spec     JobSpec    `json:"spec"`
+	}
+
+	// JobSpec is the spec of a Job.
+	JobSpec struct {
+		Parallelism *int32          `json:"parallelism,omitempty"`
+		Template    PodTemplateSpec `json:"template"`
+	}
+
+	// LabelSelector selects objects by label.
+	LabelSelector struct {
+		MatchLabels map[string]string `json:"matchLabels,omitempty"`
+	}
+
+	// PodTemplateSpec describes the pods created by a controller.
+	PodTemplateSpec struct {
+		Metadata ObjectMeta `json:"metadata,omitempty"`
+		Spec     PodSpec    `json:"spec"`
+	}
+
+	// PodSpec is the spec of a pod.
+	PodSpec struct {
+		Containers    []Container `json:"containers"`
+		Volumes       []Volume    `json:"volumes,omitempty"`
+		RestartPolicy string      `json:"restartPolicy,omitempty"`
+	}
+
+	// Container is a single container within a pod.
+	Container struct {
+		Name         string               `json:"name"`
+		Image        string               `json:"image"`
+		Command      []string             `json:"command,omitempty"`
+		Args         []string             `json:"args,omitempty"`
+		Env          []EnvVar             `json:"env,omitempty"`
+		Resources    ResourceRequirements `json:"resources,omitempty"`
+		VolumeMounts []VolumeMount        `json:"volumeMounts,omitempty"`
+	}
+
+	// EnvVar is a single environment variable in a container.
+	EnvVar struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	// ResourceRequirements describes the compute resources for a container.
+	ResourceRequirements struct {
+		Limits   map[string]string `json:"limits,omitempty"`
+		Requests map[string]string `json:"requests,omitempty"`
+	}
+
+	// Volume is a named volume in a pod. Sous only uses host path volumes.
+	Volume struct {
+		Name     string          `json:"name"`
+		HostPath *HostPathSource `json:"hostPath,omitempty"`
+	}
+
+	// HostPathSource is a volume backed by a path on the node.
+	HostPathSource struct {
+		Path string `json:"path"`
+	}
+
+	// VolumeMount mounts a Volume into a container.
+	VolumeMount struct {
+		Name      string `json:"name"`
+		MountPath string `json:"mountPath"`
+		ReadOnly  bool   `json:"readOnly,omitempty"`
+	}
+
+	deploymentList struct {
+		Items []*DeploymentObject `json:"items"`
+	}
+
+	cronJobList struct {
+		Items []*CronJobObject `json:"items"`
+	}
+)
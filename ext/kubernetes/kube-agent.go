@@ -0,0 +1,164 @@
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultNamespace is the Kubernetes namespace Sous manages objects in.
+	DefaultNamespace = "default"
+
+	deploymentsPath = "/apis/apps/v1/namespaces/%s/deployments"
+	cronJobsPath    = "/apis/batch/v1/namespaces/%s/cronjobs"
+)
+
+// KubeAgent is an implementation of the kubeClient interface, which talks to
+// the Kubernetes API servers named by each cluster's BaseURL.
+type KubeAgent struct {
+	// Namespace is the namespace objects are read from and written to.
+	Namespace string
+	// Client is the HTTP client used to talk to API servers.
+	Client *http.Client
+}
+
+// NewKubeAgent returns a set-up KubeAgent
+func NewKubeAgent() *KubeAgent {
+	return &KubeAgent{
+		Namespace: DefaultNamespace,
+		Client:    http.DefaultClient,
+	}
+}
+
+// ListDeployments returns all the Sous-managed Deployments on a cluster.
+func (ka *KubeAgent) ListDeployments(cluster string) ([]*DeploymentObject, error) {
+	list := deploymentList{}
+	err := ka.list(cluster, deploymentsPath, &list)
+	return list.Items, errors.Wrapf(err, "listing deployments on %s", cluster)
+}
+
+// ListCronJobs returns all the Sous-managed CronJobs on a cluster.
+func (ka *KubeAgent) ListCronJobs(cluster string) ([]*CronJobObject, error) {
+	list := cronJobList{}
+	err := ka.list(cluster, cronJobsPath, &list)
+	return list.Items, errors.Wrapf(err, "listing cronjobs on %s", cluster)
+}
+
+// PutDeployment creates the Deployment if it doesn't exist, and replaces it
+// if it does.
+func (ka *KubeAgent) PutDeployment(cluster string, d *DeploymentObject) error {
+	Log.Debug.Printf("Putting deployment %s %s", cluster, d.Metadata.Name)
+	existing := &DeploymentObject{}
+	err := ka.put(cluster, deploymentsPath, d.Metadata.Name, d, existing,
+		func() string { return existing.Metadata.ResourceVersion },
+		func(rv string) { d.Metadata.ResourceVersion = rv })
+	return errors.Wrapf(err, "putting deployment %s", d.Metadata.Name)
+}
+
+// PutCronJob creates the CronJob if it doesn't exist, and replaces it if it
+// does.
+func (ka *KubeAgent) PutCronJob(cluster string, cj *CronJobObject) error {
+	Log.Debug.Printf("Putting cronjob %s %s", cluster, cj.Metadata.Name)
+	existing := &CronJobObject{}
+	err := ka.put(cluster, cronJobsPath, cj.Metadata.Name, cj, existing,
+		func() string { return existing.Metadata.ResourceVersion },
+		func(rv string) { cj.Metadata.ResourceVersion = rv })
+	return errors.Wrapf(err, "putting cronjob %s", cj.Metadata.Name)
+}
+
+// DeleteDeployment deletes the named Deployment.
+func (ka *KubeAgent) DeleteDeployment(cluster, name string) error {
+	Log.Debug.Printf("Deleting deployment %s %s", cluster, name)
+	_, err := ka.do(cluster, "DELETE", ka.itemPath(deploymentsPath, name), nil, nil)
+	return errors.Wrapf(err, "deleting deployment %s", name)
+}
+
+// DeleteCronJob deletes the named CronJob.
+func (ka *KubeAgent) DeleteCronJob(cluster, name string) error {
+	Log.Debug.Printf("Deleting cronjob %s %s", cluster, name)
+	_, err := ka.do(cluster, "DELETE", ka.itemPath(cronJobsPath, name), nil, nil)
+	return errors.Wrapf(err, "deleting cronjob %s", name)
+}
+
+func (ka *KubeAgent) collectionPath(pathFmt string) string {
+	return fmt.Sprintf(pathFmt, ka.Namespace)
+}
+
+func (ka *KubeAgent) itemPath(pathFmt, name string) string {
+	return ka.collectionPath(pathFmt) + "/" + name
+}
+
+func (ka *KubeAgent) list(cluster, pathFmt string, into interface{}) error {
+	p := ka.collectionPath(pathFmt) + "?labelSelector=" + url.QueryEscape(managedBySelector)
+	_, err := ka.do(cluster, "GET", p, nil, into)
+	return err
+}
+
+// put reads the current version of an object to decide between creating and
+// replacing it, since the API server demands the current resourceVersion on
+// replacement.
+func (ka *KubeAgent) put(cluster, pathFmt, name string, obj, existing interface{},
+	currentVersion func() string, setVersion func(string)) error {
+	status, err := ka.do(cluster, "GET", ka.itemPath(pathFmt, name), nil, existing)
+	if status == http.StatusNotFound {
+		_, err = ka.do(cluster, "POST", ka.collectionPath(pathFmt), obj, nil)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	setVersion(currentVersion())
+	_, err = ka.do(cluster, "PUT", ka.itemPath(pathFmt, name), obj, nil)
+	return err
+}
+
+func (ka *KubeAgent) do(cluster, method, path string, body, into interface{}) (int, error) {
+	u, err := url.Parse(cluster)
+	if err != nil {
+		return 0, err
+	}
+	ref, err := url.Parse(path)
+	if err != nil {
+		return 0, err
+	}
+	u = u.ResolveReference(ref)
+
+	var buf io.Reader
+	if body != nil {
+		b := &bytes.Buffer{}
+		if err := json.NewEncoder(b).Encode(body); err != nil {
+			return 0, err
+		}
+		buf = b
+	}
+	rq, err := http.NewRequest(method, u.String(), buf)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		rq.Header.Set("Content-Type", "application/json")
+	}
+	rq.Header.Set("Accept", "application/json")
+
+	rz, err := ka.Client.Do(rq)
+	if err != nil {
+		return 0, err
+	}
+	defer rz.Body.Close()
+
+	if rz.StatusCode < 200 || rz.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(rz.Body)
+		return rz.StatusCode, errors.Errorf("%s %s: %s: %s", method, u, rz.Status, msg)
+	}
+	if into == nil {
+		return rz.StatusCode, nil
+	}
+	return rz.StatusCode, json.NewDecoder(rz.Body).Decode(into)
+}
@@ -0,0 +1,8 @@
+package kubernetes
+
+import "github.com/opentable/sous/lib"
+
+var (
+	// Log is an alias to sous.Log
+	Log = sous.Log
+)
@@ -0,0 +1,203 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+const (
+	labelPrefix = "sous.opentable.com/"
+
+	managedByLabel    = "app.kubernetes.io/managed-by"
+	managedByValue    = "sous"
+	managedBySelector = managedByLabel + "=" + managedByValue
+
+	// The label used to tie pods to their controlling object.
+	nameLabel = labelPrefix + "name"
+
+	// Annotations carry the parts of a DeployID that aren't recoverable from
+	// the image labels, plus the few fields Kubernetes has no place for.
+	clusterAnnotation = labelPrefix + "cluster"
+	flavorAnnotation  = labelPrefix + "flavor"
+	ownersAnnotation  = labelPrefix + "owners"
+	kindAnnotation    = labelPrefix + "kind"
+	portsAnnotation   = labelPrefix + "ports"
+	// Pods have no numbered ports to probe, so health checks are only
+	// recorded.
+	healthCheckAnnotation = labelPrefix + "health-check"
+
+	containerName = "app"
+
+	// Kubernetes object names are limited to DNS subdomains, and CronJob
+	// names further to 52 characters.
+	maxNameBase = 40
+)
+
+var illegalNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// MakeObjectName creates a Kubernetes object name from a sous.DeployID. The
+// name is a readable prefix of the ID, plus a hash of the whole ID to keep
+// truncated names unique.
+func MakeObjectName(did sous.DeployID) string {
+	full := fmt.Sprintf("%s:%s:%s", did.ManifestID.Source, did.ManifestID.Flavor, did.Cluster)
+	h := fnv.New32a()
+	h.Write([]byte(full))
+
+	base := illegalNameChars.ReplaceAllString(strings.ToLower(full), "-")
+	if len(base) > maxNameBase {
+		base = base[len(base)-maxNameBase:]
+	}
+	base = strings.Trim(base, "-")
+	return fmt.Sprintf("%s-%08x", base, h.Sum32())
+}
+
+// isCronJob reports whether a deployment of a kind should be run as a
+// CronJob, rather than a Deployment.
+func isCronJob(kind sous.ManifestKind) (bool, error) {
+	switch kind {
+	default:
+		return false, errors.Errorf("kubernetes deployer can't handle manifest kind: %v", kind)
+	case sous.ManifestKindService, sous.ManifestKindWorker:
+		return false, nil
+	case sous.ManifestKindScheduled, sous.ScheduledJob:
+		return true, nil
+	}
+}
+
+func objectMeta(d *sous.Deployment) ObjectMeta {
+	meta := ObjectMeta{
+		Name: MakeObjectName(d.ID()),
+		Labels: map[string]string{
+			managedByLabel: managedByValue,
+		},
+		Annotations: map[string]string{
+			clusterAnnotation: d.ClusterName,
+			flavorAnnotation:  d.Flavor,
+			ownersAnnotation:  strings.Join(d.Owners.Slice(), ","),
+			kindAnnotation:    string(d.Kind),
+			portsAnnotation:   strconv.Itoa(int(d.Resources.Ports())),
+		},
+	}
+	if d.HealthCheck.Enabled() {
+		// Marshalling a struct of strings and ints can't fail.
+		hc, _ := json.Marshal(d.HealthCheck)
+		meta.Annotations[healthCheckAnnotation] = string(hc)
+	}
+	return meta
+}
+
+func podTemplate(name, imageName string, d *sous.Deployment) PodTemplateSpec {
+	env := make([]EnvVar, 0, len(d.Env))
+	for k, v := range d.Env {
+		env = append(env, EnvVar{Name: k, Value: v})
+	}
+	sort.Sort(byEnvName(env))
+
+	var vols []Volume
+	var mounts []VolumeMount
+	for i, v := range d.DeployConfig.Volumes {
+		if v == nil {
+			Log.Warn.Printf("nil volume")
+			continue
+		}
+		vn := fmt.Sprintf("vol-%d", i)
+		vols = append(vols, Volume{Name: vn, HostPath: &HostPathSource{Path: v.Host}})
+		mounts = append(mounts, VolumeMount{
+			Name:      vn,
+			MountPath: v.Container,
+			ReadOnly:  v.Mode == sous.ReadOnly,
+		})
+	}
+
+	var command []string
+	if d.Command != "" {
+		command = []string{d.Command}
+	}
+
+	return PodTemplateSpec{
+		Metadata: ObjectMeta{
+			Labels: map[string]string{nameLabel: name},
+		},
+		Spec: PodSpec{
+			Containers: []Container{{
+				Name:         containerName,
+				Image:        imageName,
+				Command:      command,
+				Args:         d.Args,
+				Env:          env,
+				Resources:    mapResources(d.Resources),
+				VolumeMounts: mounts,
+			}},
+			Volumes: vols,
+		},
+	}
+}
+
+// mapResources produces the Kubernetes rendering of sous.Resources. Requests
+// and limits are set the same, which is closest to Singularity's behaviour.
+func mapResources(r sous.Resources) ResourceRequirements {
+	rs := map[string]string{
+		"cpu":    fmt.Sprintf("%dm", int64(r.Cpus()*1000+0.5)),
+		"memory": fmt.Sprintf("%dMi", int64(r.Memory()+0.5)),
+	}
+	limits := make(map[string]string, len(rs))
+	for k, v := range rs {
+		limits[k] = v
+	}
+	return ResourceRequirements{Requests: rs, Limits: limits}
+}
+
+func buildDeploymentObject(imageName string, d *sous.Deployment) *DeploymentObject {
+	meta := objectMeta(d)
+	replicas := int32(d.NumInstances)
+	return &DeploymentObject{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   meta,
+		Spec: DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &LabelSelector{MatchLabels: map[string]string{nameLabel: meta.Name}},
+			Template: podTemplate(meta.Name, imageName, d),
+		},
+	}
+}
+
+func buildCronJobObject(imageName string, d *sous.Deployment) *CronJobObject {
+	meta := objectMeta(d)
+	parallelism := int32(d.NumInstances)
+	tmpl := podTemplate(meta.Name, imageName, d)
+	tmpl.Spec.RestartPolicy = "OnFailure"
+	var timeZone *string
+	if d.ScheduleTimeZone != "" {
+		timeZone = &d.ScheduleTimeZone
+	}
+	return &CronJobObject{
+		APIVersion: "batch/v1",
+		Kind:       "CronJob",
+		Metadata:   meta,
+		Spec: CronJobSpec{
+			Schedule: d.Schedule,
+			TimeZone: timeZone,
+			JobTemplate: JobTemplateSpec{
+				Spec: JobSpec{
+					Parallelism: &parallelism,
+					Template:    tmpl,
+				},
+			},
+		},
+	}
+}
+
+type byEnvName []EnvVar
+
+func (e byEnvName) Len() int           { return len(e) }
+func (e byEnvName) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e byEnvName) Less(i, j int) bool { return e[i].Name < e[j].Name }
@@ -0,0 +1,37 @@
+// Package secrets provides implementations of sous.SecretProvider.
+package secrets
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FileProvider is a sous.SecretProvider which keeps each secret in a
+// directory tree: the value of secret://path/key is the content of the file
+// Dir/path/key, less any trailing newline. It is meant for local use and
+// tests.
+type FileProvider struct {
+	Dir string
+}
+
+// NewFileProvider returns a FileProvider reading secrets from dir.
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{Dir: dir}
+}
+
+// Secret implements sous.SecretProvider.
+func (fp *FileProvider) Secret(path, key string) (string, error) {
+	root := filepath.Clean(fp.Dir)
+	file := filepath.Join(root, filepath.FromSlash(path), key)
+	if !strings.HasPrefix(file, root+string(filepath.Separator)) {
+		return "", errors.Errorf("secret %s/%s is outside %s", path, key, fp.Dir)
+	}
+	value, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading secret %s/%s", path, key)
+	}
+	return strings.TrimRight(string(value), "\r\n"), nil
+}
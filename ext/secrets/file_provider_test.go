@@ -0,0 +1,33 @@
+package secrets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+func TestFileProvider(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "sous-secrets")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	require.NoError(os.MkdirAll(filepath.Join(dir, "db", "prod"), 0700))
+	require.NoError(ioutil.WriteFile(filepath.Join(dir, "db", "prod", "password"), []byte("hunter2\n"), 0600))
+
+	fp := NewFileProvider(dir)
+	value, err := fp.Secret("db/prod", "password")
+	assert.NoError(err)
+	assert.Equal("hunter2", value)
+
+	_, err = fp.Secret("db/prod", "missing")
+	assert.Error(err)
+
+	_, err = fp.Secret("../..", "etc")
+	assert.Error(err)
+}
@@ -61,6 +61,7 @@ func TestGetDepSetWorks(t *testing.T) {
 			whip[url] = co
 			return cl
 		},
+		nil,
 	}
 
 	clusters := sous.Clusters{"test": {BaseURL: "http://test-singularity.org/"}}
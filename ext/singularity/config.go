@@ -0,0 +1,15 @@
+package singularity
+
+// Config is the configuration for Singularity clusters.
+type Config struct {
+	// DeployTimeout is the number of seconds to wait for each Singularity
+	// deploy to succeed or fail. If it is zero, deploys are considered done
+	// once Singularity has accepted them.
+	DeployTimeout int `env:"SOUS_SINGULARITY_DEPLOY_TIMEOUT"`
+}
+
+// DefaultConfig builds a default configuration, which can be then overridden by
+// client code.
+func DefaultConfig() Config {
+	return Config{}
+}
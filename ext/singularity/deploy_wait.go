@@ -0,0 +1,115 @@
+package singularity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opentable/go-singularity/dtos"
+)
+
+type (
+	// DeployFailed is returned when Singularity reports that a deploy did not
+	// succeed.
+	DeployFailed struct {
+		RequestID, DeployID string
+		// State is the final state of the deploy, e.g. FAILED or CANCELED.
+		State string
+		// Reasons are the messages Singularity gave about the failure.
+		Reasons []string
+	}
+
+	// DeployTimedOut is returned when a deploy neither succeeds nor fails
+	// within the configured timeout.
+	DeployTimedOut struct {
+		RequestID, DeployID string
+		Timeout             time.Duration
+		// LastError is the error from the last attempt to check the deploy, if
+		// there was one.
+		LastError error
+	}
+)
+
+// DefaultPollInterval is how often a RectiAgent checks on a deploy it is
+// waiting for.
+const DefaultPollInterval = 2 * time.Second
+
+func (df *DeployFailed) Error() string {
+	msg := fmt.Sprintf("deploy %s of %s %s", df.DeployID, df.RequestID, df.State)
+	if len(df.Reasons) == 0 {
+		return msg
+	}
+	return msg + ": " + strings.Join(df.Reasons, "; ")
+}
+
+func (dt *DeployTimedOut) Error() string {
+	msg := fmt.Sprintf("deploy %s of %s not finished after %s", dt.DeployID, dt.RequestID, dt.Timeout)
+	if dt.LastError == nil {
+		return msg
+	}
+	return fmt.Sprintf("%s (last error: %s)", msg, dt.LastError)
+}
+
+// awaitDeploy polls Singularity until the identified deploy succeeds, fails,
+// or the agent's DeployTimeout passes.
+func (ra *RectiAgent) awaitDeploy(cluster, reqID, depID string) error {
+	interval := ra.PollInterval
+	if interval == 0 {
+		interval = DefaultPollInterval
+	}
+	client := ra.singularityClient(cluster)
+	deadline := time.Now().Add(ra.DeployTimeout)
+
+	var lastErr error
+	for {
+		// Until the deploy starts, Singularity's history won't know it, so
+		// errors here aren't final.
+		dh, err := client.GetDeploy(reqID, depID)
+		lastErr = err
+		if err == nil {
+			if done, err := deployOutcome(reqID, depID, dh); done {
+				return err
+			}
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return &DeployTimedOut{RequestID: reqID, DeployID: depID, Timeout: ra.DeployTimeout, LastError: lastErr}
+		}
+		time.Sleep(interval)
+	}
+}
+
+// deployOutcome returns true if the deploy has finished, along with an error
+// if it finished unsuccessfully.
+func deployOutcome(reqID, depID string, dh *dtos.SingularityDeployHistory) (bool, error) {
+	if dh == nil || dh.DeployResult == nil {
+		return false, nil
+	}
+	result := dh.DeployResult
+	switch result.DeployState {
+	default:
+		return false, nil
+	case dtos.SingularityDeployResultDeployStateSUCCEEDED:
+		Log.Debug.Printf("Deploy %s of %s succeeded", depID, reqID)
+		return true, nil
+	case dtos.SingularityDeployResultDeployStateFAILED,
+		dtos.SingularityDeployResultDeployStateFAILED_INTERNAL_STATE,
+		dtos.SingularityDeployResultDeployStateCANCELED,
+		dtos.SingularityDeployResultDeployStateOVERDUE:
+	}
+
+	df := &DeployFailed{RequestID: reqID, DeployID: depID, State: string(result.DeployState)}
+	if result.Message != "" {
+		df.Reasons = append(df.Reasons, result.Message)
+	}
+	for _, f := range result.DeployFailures {
+		if f == nil {
+			continue
+		}
+		reason := string(f.Reason)
+		if f.Message != "" {
+			reason += ": " + f.Message
+		}
+		df.Reasons = append(df.Reasons, reason)
+	}
+	return true, df
+}
@@ -25,15 +25,16 @@ type (
 		Client   rectificationClient
 		Registry sous.Registry
 		singFac  func(string) *singularity.Client
+		limits   *rateLimits
 	}
 
 	// rectificationClient abstracts the raw interactions with Singularity.
 	rectificationClient interface {
 		// Deploy creates a new deploy on a particular requeust
-		Deploy(cluster, depID, reqID, dockerImage string, r sous.Resources, e sous.Env, vols sous.Volumes) error
+		Deploy(cluster, depID, reqID, dockerImage string, r sous.Resources, e sous.Env, vols sous.Volumes, command string, args []string, hc sous.HealthCheck) error
 
 		// PostRequest sends a request to a Singularity cluster to initiate
-		PostRequest(cluster, reqID string, instanceCount int, kind sous.ManifestKind, owners sous.OwnerSet) error
+		PostRequest(cluster, reqID string, instanceCount int, kind sous.ManifestKind, owners sous.OwnerSet, schedule, timeZone string) error
 
 		// DeleteRequest instructs Singularity to delete a particular request
 		DeleteRequest(cluster, reqID, message string) error
@@ -45,7 +46,7 @@ type (
 
 // NewDeployer creates a new Singularity-based sous.Deployer.
 func NewDeployer(r sous.Registry, c rectificationClient) sous.Deployer {
-	return &deployer{Client: c, Registry: r}
+	return &deployer{Client: c, Registry: r, limits: newRateLimits()}
 }
 
 func (r *deployer) RectifyCreates(cc <-chan *sous.Deployment, errs chan<- sous.RectificationError) {
@@ -62,7 +63,7 @@ func (r *deployer) SetSingularityFactory(fn func(string) *singularity.Client) {
 
 func (r *deployer) buildSingClient(url string) *singularity.Client {
 	if r.singFac == nil {
-		return singularity.NewClient(url)
+		return newSingularityClient(url)
 	}
 	return r.singFac(url)
 }
@@ -92,12 +93,14 @@ func (r *deployer) RectifySingleCreate(d *sous.Deployment) (err error) {
 		return err
 	}
 	reqID := computeRequestID(d)
-	if err = r.Client.PostRequest(d.Cluster.BaseURL, reqID, d.NumInstances, d.Kind, d.Owners); err != nil {
+	r.limits.wait(d.Cluster)
+	if err = r.Client.PostRequest(d.Cluster.BaseURL, reqID, d.NumInstances, d.Kind, d.Owners, d.Schedule, d.ScheduleTimeZone); err != nil {
 		return err
 	}
+	r.limits.wait(d.Cluster)
 	return r.Client.Deploy(
 		d.Cluster.BaseURL, newDepID(), reqID, name, d.Resources,
-		d.Env, d.DeployConfig.Volumes)
+		d.Env, d.DeployConfig.Volumes, d.Command, d.Args, d.HealthCheck)
 }
 
 func (r *deployer) RectifyDeletes(dc <-chan *sous.Deployment, errs chan<- sous.RectificationError) {
@@ -111,34 +114,56 @@ func (r *deployer) RectifyDeletes(dc <-chan *sous.Deployment, errs chan<- sous.R
 func (r *deployer) RectifySingleDelete(d *sous.Deployment) (err error) {
 	defer rectifyRecover(d, "RectifySingleDelete", &err)
 	requestID := computeRequestID(d)
-	// TODO: Alert the owner of this request that there is no manifest for it;
-	// they should either delete the request manually, or else add the manifest back.
-	sous.Log.Warn.Printf("NOT DELETING REQUEST %q (FOR: %q)", requestID, d.ID())
-	return nil
-	// The following line deletes requests when it is not commented out.
-	//return r.Client.DeleteRequest(d.Cluster.BaseURL, requestID, "deleting request for removed manifest")
+	// Deployments only arrive here once the cluster's DeletePolicy allows it;
+	// see sous.Resolver.
+	sous.Log.Warn.Printf("DELETING REQUEST %q (FOR: %q)", requestID, d.ID())
+	r.limits.wait(d.Cluster)
+	return r.Client.DeleteRequest(d.Cluster.BaseURL, requestID, "deleting request for removed manifest")
 }
 
 func (r *deployer) RectifyModifies(
 	mc <-chan *sous.DeploymentPair, errs chan<- sous.RectificationError) {
 	for pair := range mc {
 		if err := r.RectifySingleModification(pair); err != nil {
+			if rbe, ok := err.(*sous.RollbackError); ok {
+				errs <- rbe
+				continue
+			}
 			errs <- &sous.ChangeError{Deployments: pair, Err: err}
 		}
 	}
 }
 
+// RectifySingleModification changes a deployment. If the new version fails to
+// roll out, and the deployment has rollback enabled, the prior version is
+// deployed again, and a *sous.RollbackError is returned. Rollouts can only be
+// seen to fail if the RectiAgent waits for deploys.
 func (r *deployer) RectifySingleModification(pair *sous.DeploymentPair) (err error) {
 	Log.Debug.Printf("Rectifying modified %q: \n  %# v \n    =>  \n  %# v", pair.ID(), pair.Prior, pair.Post)
 	defer rectifyRecover(pair, "RectifySingleModification", &err)
+	err = r.change(pair)
+	if err == nil || !pair.Post.RollbackEnabled() || !isRolloutFailure(err) {
+		return err
+	}
+
+	Log.Warn.Printf("Rolling back %q in %q to version %s: %s",
+		pair.Post.ID().ManifestID, pair.Post.ClusterName, pair.Prior.SourceID.Version, err)
+	back := &sous.DeploymentPair{Prior: pair.Post, Post: pair.Prior}
+	return &sous.RollbackError{Deployments: pair, Err: err, RollbackErr: r.change(back)}
+}
+
+func (r *deployer) change(pair *sous.DeploymentPair) error {
 	if r.changesReq(pair) {
 		Log.Debug.Printf("Updating Request...")
+		r.limits.wait(pair.Post.Cluster)
 		if err := r.Client.PostRequest(
 			pair.Post.Cluster.BaseURL,
 			computeRequestID(pair.Post),
 			pair.Post.NumInstances,
 			pair.Post.Kind,
 			pair.Post.Owners,
+			pair.Post.Schedule,
+			pair.Post.ScheduleTimeZone,
 		); err != nil {
 			return err
 		}
@@ -151,6 +176,7 @@ func (r *deployer) RectifySingleModification(pair *sous.DeploymentPair) (err err
 			return err
 		}
 
+		r.limits.wait(pair.Post.Cluster)
 		if err := r.Client.Deploy(
 			pair.Post.Cluster.BaseURL,
 			newDepID(),
@@ -159,6 +185,9 @@ func (r *deployer) RectifySingleModification(pair *sous.DeploymentPair) (err err
 			pair.Post.Resources,
 			pair.Post.Env,
 			pair.Post.DeployConfig.Volumes,
+			pair.Post.Command,
+			pair.Post.Args,
+			pair.Post.HealthCheck,
 		); err != nil {
 			return err
 		}
@@ -166,15 +195,32 @@ func (r *deployer) RectifySingleModification(pair *sous.DeploymentPair) (err err
 	return nil
 }
 
+// isRolloutFailure returns true if err shows that Singularity tried and
+// failed to deploy, rather than that the deploy was never made.
+func isRolloutFailure(err error) bool {
+	switch errors.Cause(err).(type) {
+	default:
+		return false
+	case *DeployFailed, *DeployTimedOut:
+		return true
+	}
+}
+
 func (r deployer) changesReq(pair *sous.DeploymentPair) bool {
-	return pair.Prior.NumInstances != pair.Post.NumInstances
+	return pair.Prior.NumInstances != pair.Post.NumInstances ||
+		!pair.Prior.Owners.Equal(pair.Post.Owners) ||
+		pair.Prior.Schedule != pair.Post.Schedule ||
+		pair.Prior.ScheduleTimeZone != pair.Post.ScheduleTimeZone
 }
 
 func changesDep(pair *sous.DeploymentPair) bool {
 	return !(pair.Prior.SourceID.Equal(pair.Post.SourceID) &&
 		pair.Prior.Resources.Equal(pair.Post.Resources) &&
 		pair.Prior.Env.Equal(pair.Post.Env) &&
-		pair.Prior.DeployConfig.Volumes.Equal(pair.Post.DeployConfig.Volumes))
+		pair.Prior.DeployConfig.Volumes.Equal(pair.Post.DeployConfig.Volumes) &&
+		pair.Prior.Command == pair.Post.Command &&
+		sous.ArgsEqual(pair.Prior.Args, pair.Post.Args) &&
+		pair.Prior.HealthCheck == pair.Post.HealthCheck)
 }
 
 func computeRequestID(d *sous.Deployment) string {
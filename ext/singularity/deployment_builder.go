@@ -204,11 +204,15 @@ func (db *deploymentBuilder) assignClusterName() error {
 }
 
 func (db *deploymentBuilder) unpackDeployConfig() error {
-	db.Target.Env = db.deploy.Env
-	Log.Vomit.Printf("Env: %+v", db.deploy.Env)
-	if db.Target.Env == nil {
-		db.Target.Env = make(map[string]string)
+	db.Target.Env = make(sous.Env, len(db.deploy.Env))
+	for name, value := range db.deploy.Env {
+		// Report the secret reference, never the secret.
+		if ref, ok := db.deploy.Metadata[secretMetadataPrefix+name]; ok {
+			value = ref
+		}
+		db.Target.Env[name] = value
 	}
+	Log.Vomit.Printf("Env: %+v", db.Target.Env)
 
 	singRez := db.deploy.Resources
 	if singRez == nil {
@@ -220,6 +224,21 @@ func (db *deploymentBuilder) unpackDeployConfig() error {
 	db.Target.Resources["ports"] = fmt.Sprintf("%d", singRez.NumPorts)
 
 	db.Target.NumInstances = int(db.request.Instances)
+	db.Target.Command = db.deploy.Command
+	if len(db.deploy.Arguments) > 0 {
+		db.Target.Args = []string(db.deploy.Arguments)
+	}
+	if db.deploy.HealthcheckUri != "" {
+		db.Target.HealthCheck = sous.HealthCheck{
+			URIPath:             db.deploy.HealthcheckUri,
+			PortIndex:           int(db.deploy.HealthcheckPortIndex),
+			StartupDelaySeconds: int(db.deploy.HealthcheckMaxTotalTimeoutSeconds),
+			TimeoutSeconds:      int(db.deploy.HealthcheckTimeoutSeconds),
+			IntervalSeconds:     int(db.deploy.HealthcheckIntervalSeconds),
+		}
+	}
+	db.Target.Schedule = db.request.Schedule
+	db.Target.ScheduleTimeZone = db.request.ScheduleTimeZone
 	db.Target.Owners = make(sous.OwnerSet)
 	for _, o := range db.request.Owners {
 		db.Target.Owners.Add(o)
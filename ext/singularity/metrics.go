@@ -0,0 +1,21 @@
+package singularity
+
+import (
+	"github.com/opentable/go-singularity"
+	"github.com/opentable/sous/util/metrics"
+	"github.com/opentable/swaggering"
+)
+
+var requestDurations = metrics.Default.NewHistogram("sous_singularity_request_duration_seconds",
+	"How long requests to Singularity took, by HTTP method and response code.",
+	nil, "method", "code")
+
+// newSingularityClient builds a client for the Singularity at url, which
+// observes how long its requests take.
+func newSingularityClient(url string) *singularity.Client {
+	cl := singularity.NewClient(url)
+	if gc, ok := cl.Requester.(*swaggering.GenericClient); ok {
+		gc.HTTP.Transport = metrics.InstrumentTransport(gc.HTTP.Transport, requestDurations)
+	}
+	return cl
+}
@@ -0,0 +1,55 @@
+package singularity
+
+import (
+	"sync"
+	"time"
+
+	"github.com/opentable/sous/lib"
+)
+
+type (
+	// rateLimits throttles the requests which create, change or delete
+	// deployments in each Singularity cluster, according to the cluster's
+	// RateLimit. Reads aren't throttled.
+	rateLimits struct {
+		sync.Mutex
+		limiters map[string]*rateLimiter
+	}
+
+	rateLimiter struct {
+		sync.Mutex
+		interval time.Duration
+		next     time.Time
+	}
+)
+
+func newRateLimits() *rateLimits {
+	return &rateLimits{limiters: make(map[string]*rateLimiter)}
+}
+
+// wait blocks until another request may be made to the cluster.
+func (rl *rateLimits) wait(c *sous.Cluster) {
+	if rl == nil || c == nil || c.RateLimit <= 0 {
+		return
+	}
+	rl.Lock()
+	l, ok := rl.limiters[c.BaseURL]
+	if !ok {
+		l = &rateLimiter{}
+		rl.limiters[c.BaseURL] = l
+	}
+	rl.Unlock()
+	l.wait(time.Duration(float64(time.Second) / c.RateLimit))
+}
+
+func (l *rateLimiter) wait(interval time.Duration) {
+	l.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(interval)
+	l.Unlock()
+	time.Sleep(delay)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"sync"
+	"time"
 
 	"github.com/opentable/go-singularity"
 	"github.com/opentable/go-singularity/dtos"
@@ -24,8 +25,22 @@ type RectiAgent struct {
 	singClients map[string]*singularity.Client
 	sync.RWMutex
 	nameCache sous.Registry
+	// DeployTimeout is how long Deploy waits for Singularity to report that
+	// the deploy succeeded or failed. If it is zero, Deploy returns as soon as
+	// the deploy has been accepted.
+	DeployTimeout time.Duration
+	// PollInterval is how often to check on a deploy while waiting for it.
+	// It defaults to DefaultPollInterval.
+	PollInterval time.Duration
+	// Secrets resolves secret references in the Env of each deploy.
+	Secrets sous.SecretProvider
 }
 
+// secretMetadataPrefix prefixes the deploy metadata which records the secret
+// reference each resolved env var came from, so that the reference, rather
+// than the secret, can be read back.
+const secretMetadataPrefix = "sous.secret."
+
 // NewRectiAgent returns a set-up RectiAgent
 func NewRectiAgent(b sous.Registry) *RectiAgent {
 	return &RectiAgent{
@@ -46,19 +61,51 @@ func mapResources(r sous.Resources) dtoMap {
 
 // Deploy sends requests to Singularity to make a deployment happen
 func (ra *RectiAgent) Deploy(cluster, depID, reqID, dockerImage string,
-	r sous.Resources, e sous.Env, vols sous.Volumes) error {
-	Log.Debug.Printf("Deploying instance %s %s %s %s %v %v", cluster, depID, reqID, dockerImage, r, e)
-	depReq, err := buildDeployRequest(dockerImage, e, r, reqID, vols)
+	r sous.Resources, e sous.Env, vols sous.Volumes, command string, args []string, hc sous.HealthCheck) error {
+	Log.Debug.Printf("Deploying instance %s %s %s %s %v %v %q %q %v", cluster, depID, reqID, dockerImage, r, e, command, args, hc)
+	depReq, err := buildDeployRequest(dockerImage, e, r, reqID, depID, vols, command, args, hc)
 	if err != nil {
 		return err
 	}
 
 	Log.Debug.Printf("Deploy req: %+ v", depReq)
-	_, err = ra.singularityClient(cluster).Deploy(depReq)
-	return err
+	if err := resolveSecrets(depReq.Deploy, e, ra.Secrets); err != nil {
+		return err
+	}
+	if _, err = ra.singularityClient(cluster).Deploy(depReq); err != nil {
+		return err
+	}
+	if ra.DeployTimeout == 0 {
+		return nil
+	}
+	return ra.awaitDeploy(cluster, reqID, depID)
 }
 
-func buildDeployRequest(dockerImage string, e sous.Env, r sous.Resources, reqID string, vols sous.Volumes) (*dtos.SingularityDeployRequest, error) {
+// resolveSecrets replaces the secret references in dep's Env with their
+// values, and records the references in its Metadata.
+func resolveSecrets(dep *dtos.SingularityDeploy, e sous.Env, sp sous.SecretProvider) error {
+	refs := e.SecretRefs()
+	if len(refs) == 0 {
+		return nil
+	}
+	resolved, err := e.ResolveSecrets(sp)
+	if err != nil {
+		return err
+	}
+	metadata := map[string]string{}
+	for k, v := range dep.Metadata {
+		metadata[k] = v
+	}
+	for name, ref := range refs {
+		metadata[secretMetadataPrefix+name] = ref
+	}
+	if err := dep.SetField("Env", map[string]string(resolved)); err != nil {
+		return err
+	}
+	return dep.SetField("Metadata", metadata)
+}
+
+func buildDeployRequest(dockerImage string, e sous.Env, r sous.Resources, reqID, depID string, vols sous.Volumes, command string, args []string, hc sous.HealthCheck) (*dtos.SingularityDeployRequest, error) {
 	var depReq swaggering.Fielder
 	dockerInfo, err := swaggering.LoadMap(&dtos.SingularityDockerInfo{}, dtoMap{
 		"Image":   dockerImage,
@@ -99,13 +146,34 @@ func buildDeployRequest(dockerImage string, e sous.Env, r sous.Resources, reqID
 		return nil, err
 	}
 
-	dep, err := swaggering.LoadMap(&dtos.SingularityDeploy{}, dtoMap{
-		"Id":            MakeDeployID(uuid.NewV4().String()),
+	depFields := dtoMap{
+		"Id":            depID,
 		"RequestId":     reqID,
 		"Resources":     res,
 		"ContainerInfo": ci,
 		"Env":           map[string]string(e),
-	})
+	}
+	if command != "" {
+		depFields["Command"] = command
+	}
+	if len(args) > 0 {
+		depFields["Arguments"] = swaggering.StringList(args)
+	}
+	if hc.Enabled() {
+		depFields["HealthcheckUri"] = hc.URIPath
+		depFields["HealthcheckPortIndex"] = int32(hc.PortIndex)
+		// Unset timings are left for Singularity to default.
+		if hc.StartupDelaySeconds > 0 {
+			depFields["HealthcheckMaxTotalTimeoutSeconds"] = int64(hc.StartupDelaySeconds)
+		}
+		if hc.TimeoutSeconds > 0 {
+			depFields["HealthcheckTimeoutSeconds"] = int64(hc.TimeoutSeconds)
+		}
+		if hc.IntervalSeconds > 0 {
+			depFields["HealthcheckIntervalSeconds"] = int64(hc.IntervalSeconds)
+		}
+	}
+	dep, err := swaggering.LoadMap(&dtos.SingularityDeploy{}, depFields)
 	Log.Debug.Printf("Deploy: %+ v", dep)
 	Log.Debug.Printf("  Container: %+ v", ci)
 	Log.Debug.Printf("  Docker: %+ v", dockerInfo)
@@ -117,19 +185,27 @@ func buildDeployRequest(dockerImage string, e sous.Env, r sous.Resources, reqID
 	return depReq.(*dtos.SingularityDeployRequest), nil
 }
 
-// PostRequest sends requests to Singularity to create a new Request
-func (ra *RectiAgent) PostRequest(cluster, reqID string, instanceCount int, kind sous.ManifestKind, owners sous.OwnerSet) error {
+// PostRequest sends requests to Singularity to create a new Request.
+// Scheduled requests are given their schedule, and the time zone it is in.
+func (ra *RectiAgent) PostRequest(cluster, reqID string, instanceCount int, kind sous.ManifestKind, owners sous.OwnerSet, schedule, timeZone string) error {
 	Log.Debug.Printf("Creating application %s %s %d", cluster, reqID, instanceCount)
 	reqType, err := determineRequestType(kind)
 	if err != nil {
 		return err
 	}
-	req, err := swaggering.LoadMap(&dtos.SingularityRequest{}, dtoMap{
+	reqFields := dtoMap{
 		"Id":          reqID,
 		"RequestType": reqType,
 		"Instances":   int32(instanceCount),
 		"Owners":      swaggering.StringList(owners.Slice()),
-	})
+	}
+	if schedule != "" {
+		reqFields["Schedule"] = schedule
+	}
+	if timeZone != "" {
+		reqFields["ScheduleTimeZone"] = timeZone
+	}
+	req, err := swaggering.LoadMap(&dtos.SingularityRequest{}, reqFields)
 
 	if err != nil {
 		return err
@@ -201,7 +277,7 @@ func (ra *RectiAgent) singularityClient(url string) *singularity.Client {
 	}
 	ra.Lock()
 	defer ra.Unlock()
-	cl = singularity.NewClient(url)
+	cl = newSingularityClient(url)
 	//cl.Debug = true
 	ra.singClients[url] = cl
 	return cl
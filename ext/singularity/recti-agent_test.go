@@ -1,8 +1,12 @@
 package singularity
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/nyarly/testify/assert"
 	"github.com/opentable/go-singularity/dtos"
 	sous "github.com/opentable/sous/lib"
 )
@@ -33,3 +37,69 @@ func TestDetermineRequestType(t *testing.T) {
 
 	}
 }
+
+func deployHistoryServer(responses ...string) *httptest.Server {
+	calls := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/history/request/req/deploy/dep" {
+			http.NotFound(w, r)
+			return
+		}
+		if calls >= len(responses) {
+			calls = len(responses) - 1
+		}
+		resp := responses[calls]
+		calls++
+		if resp == "" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(resp))
+	}))
+}
+
+func TestAwaitDeployFailure(t *testing.T) {
+	assert := assert.New(t)
+
+	srv := deployHistoryServer(
+		"",
+		`{"deployResult": {"deployState": "WAITING"}}`,
+		`{"deployResult": {"deployState": "FAILED", "message": "Task failed",
+			"deployFailures": [{"reason": "TASK_FAILED_ON_STARTUP", "message": "exit 1"}]}}`,
+	)
+	defer srv.Close()
+
+	ra := NewRectiAgent(sous.NewDummyRegistry())
+	ra.DeployTimeout = time.Second
+	ra.PollInterval = time.Millisecond
+
+	err := ra.awaitDeploy(srv.URL, "req", "dep")
+	if assert.IsType(&DeployFailed{}, err) {
+		df := err.(*DeployFailed)
+		assert.Equal("FAILED", df.State)
+		assert.Equal([]string{"Task failed", "TASK_FAILED_ON_STARTUP: exit 1"}, df.Reasons)
+	}
+}
+
+func TestAwaitDeploySuccess(t *testing.T) {
+	srv := deployHistoryServer(`{"deployResult": {"deployState": "SUCCEEDED"}}`)
+	defer srv.Close()
+
+	ra := NewRectiAgent(sous.NewDummyRegistry())
+	ra.DeployTimeout = time.Second
+	ra.PollInterval = time.Millisecond
+
+	assert.NoError(t, ra.awaitDeploy(srv.URL, "req", "dep"))
+}
+
+func TestAwaitDeployTimeout(t *testing.T) {
+	srv := deployHistoryServer(`{"deployResult": {"deployState": "WAITING"}}`)
+	defer srv.Close()
+
+	ra := NewRectiAgent(sous.NewDummyRegistry())
+	ra.DeployTimeout = 20 * time.Millisecond
+	ra.PollInterval = time.Millisecond
+
+	assert.IsType(t, &DeployTimedOut{}, ra.awaitDeploy(srv.URL, "req", "dep"))
+}
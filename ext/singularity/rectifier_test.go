@@ -1,13 +1,16 @@
 package singularity
 
 import (
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/nyarly/testify/assert"
 	"github.com/nyarly/testify/require"
+	"github.com/opentable/go-singularity/dtos"
 	"github.com/opentable/sous/lib"
 )
 
@@ -23,10 +26,57 @@ func TestBuildDeployRequest(t *testing.T) {
 	rez := sous.Resources{"cpus": "0.1"}
 	vols := sous.Volumes{&sous.Volume{}}
 
-	dr, err := buildDeployRequest(di, env, rez, rID, vols)
+	hc := sous.HealthCheck{URIPath: "/health", PortIndex: 1, StartupDelaySeconds: 60, TimeoutSeconds: 5, IntervalSeconds: 10}
+	dr, err := buildDeployRequest(di, env, rez, rID, "depID", vols, "/bin/app", []string{"-port", "9000"}, hc)
 	require.NoError(err)
 	assert.NotNil(dr)
 	assert.Equal(dr.Deploy.RequestId, rID)
+	assert.Equal("/bin/app", dr.Deploy.Command)
+	assert.Equal([]string{"-port", "9000"}, []string(dr.Deploy.Arguments))
+	assert.Equal("/health", dr.Deploy.HealthcheckUri)
+	assert.EqualValues(1, dr.Deploy.HealthcheckPortIndex)
+	assert.EqualValues(60, dr.Deploy.HealthcheckMaxTotalTimeoutSeconds)
+	assert.EqualValues(5, dr.Deploy.HealthcheckTimeoutSeconds)
+	assert.EqualValues(10, dr.Deploy.HealthcheckIntervalSeconds)
+
+	hc = sous.HealthCheck{URIPath: "/health"}
+	dr, err = buildDeployRequest(di, env, rez, rID, "depID", vols, "", nil, hc)
+	require.NoError(err)
+	present := dr.Deploy.FieldsPresent()
+	assert.Contains(present, "healthcheckUri")
+	assert.NotContains(present, "healthcheckMaxTotalTimeoutSeconds")
+	assert.NotContains(present, "healthcheckTimeoutSeconds")
+	assert.NotContains(present, "healthcheckIntervalSeconds")
+}
+
+type mapSecrets map[string]string
+
+func (ms mapSecrets) Secret(path, key string) (string, error) {
+	v, ok := ms[path+"/"+key]
+	if !ok {
+		return "", fmt.Errorf("no secret %s/%s", path, key)
+	}
+	return v, nil
+}
+
+func TestResolveSecrets(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	env := sous.Env{"USER": "app", "PASSWORD": "secret://db/prod/password"}
+	dr, err := buildDeployRequest("dockerImage", env, sous.Resources{}, "reqID", "depID", nil, "", nil, sous.HealthCheck{})
+	require.NoError(err)
+
+	assert.Error(resolveSecrets(dr.Deploy, env, nil))
+	require.NoError(resolveSecrets(dr.Deploy, env, mapSecrets{"db/prod/password": "hunter2"}))
+	assert.Equal("hunter2", dr.Deploy.Env["PASSWORD"])
+	assert.Equal("app", dr.Deploy.Env["USER"])
+	assert.Equal("secret://db/prod/password", dr.Deploy.Metadata[secretMetadataPrefix+"PASSWORD"])
+	assert.Equal("secret://db/prod/password", env["PASSWORD"])
+
+	db := &deploymentBuilder{deploy: dr.Deploy, request: &dtos.SingularityRequest{}}
+	require.NoError(db.unpackDeployConfig())
+	assert.Equal(env, db.Target.Env)
 }
 
 func TestModifyScale(t *testing.T) {
@@ -136,6 +186,160 @@ func TestModifyImage(t *testing.T) {
 	}
 }
 
+func TestModifyRequestAndDeployFields(t *testing.T) {
+	cases := []struct {
+		name        string
+		prior, post func(*sous.Deployment)
+		check       func(*assert.Assertions, *sous.DummyRectificationClient)
+	}{
+		{
+			name:  "owners",
+			prior: func(d *sous.Deployment) { d.Owners = sous.NewOwnerSet("judson") },
+			post:  func(d *sous.Deployment) { d.Owners = sous.NewOwnerSet("judson", "sam") },
+			check: func(assert *assert.Assertions, client *sous.DummyRectificationClient) {
+				assert.Len(client.Deployed, 0)
+				if assert.Len(client.Created, 1) {
+					assert.Equal(sous.NewOwnerSet("judson", "sam"), client.Created[0].Owners)
+				}
+			},
+		},
+		{
+			name:  "args",
+			prior: func(d *sous.Deployment) { d.Command, d.Args = "/bin/app", []string{"-v"} },
+			post:  func(d *sous.Deployment) { d.Command, d.Args = "/bin/app", []string{"-v", "-port", "9000"} },
+			check: func(assert *assert.Assertions, client *sous.DummyRectificationClient) {
+				assert.Len(client.Created, 0)
+				if assert.Len(client.Deployed, 1) {
+					assert.Equal("/bin/app", client.Deployed[0].Command)
+					assert.Equal([]string{"-v", "-port", "9000"}, client.Deployed[0].Args)
+				}
+			},
+		},
+		{
+			name: "schedule",
+			prior: func(d *sous.Deployment) {
+				d.Kind, d.Schedule, d.ScheduleTimeZone = sous.ManifestKindScheduled, "0 4 * * *", "America/Los_Angeles"
+			},
+			post: func(d *sous.Deployment) {
+				d.Kind, d.Schedule, d.ScheduleTimeZone = sous.ManifestKindScheduled, "0 5 * * *", "America/Los_Angeles"
+			},
+			check: func(assert *assert.Assertions, client *sous.DummyRectificationClient) {
+				assert.Len(client.Deployed, 0)
+				if assert.Len(client.Created, 1) {
+					assert.Equal("0 5 * * *", client.Created[0].Schedule)
+					assert.Equal("America/Los_Angeles", client.Created[0].TimeZone)
+				}
+			},
+		},
+	}
+
+	for _, c := range cases {
+		dep := func(change func(*sous.Deployment)) *sous.Deployment {
+			d := &sous.Deployment{
+				SourceID: sous.MustNewSourceID("reqid", "", "1.2.3"),
+				DeployConfig: sous.DeployConfig{
+					NumInstances: 1,
+				},
+				ClusterName: "cluster",
+				Cluster: &sous.Cluster{
+					BaseURL: "cluster",
+				},
+			}
+			change(d)
+			return d
+		}
+
+		mods := make(chan *sous.DeploymentPair, 1)
+		errs := make(chan sous.RectificationError)
+
+		nc := sous.NewDummyRegistry()
+		client := sous.NewDummyRectificationClient(nc)
+		deployer := NewDeployer(nc, client)
+
+		mods <- &sous.DeploymentPair{Prior: dep(c.prior), Post: dep(c.post)}
+		close(mods)
+		deployer.RectifyModifies(mods, errs)
+		close(errs)
+
+		for e := range errs {
+			t.Errorf("%s: %s", c.name, e)
+		}
+
+		c.check(assert.New(t), client)
+	}
+}
+
+type failingDeployClient struct {
+	*sous.DummyRectificationClient
+	failures int
+}
+
+func (fc *failingDeployClient) Deploy(cluster, depID, reqID, imageName string, res sous.Resources, e sous.Env, vols sous.Volumes, command string, args []string, hc sous.HealthCheck) error {
+	if err := fc.DummyRectificationClient.Deploy(cluster, depID, reqID, imageName, res, e, vols, command, args, hc); err != nil {
+		return err
+	}
+	if fc.failures > 0 {
+		fc.failures--
+		return &DeployFailed{RequestID: reqID, DeployID: depID, State: "FAILED"}
+	}
+	return nil
+}
+
+func TestModifyRollback(t *testing.T) {
+	assert := assert.New(t)
+	before := "1.2.3-test"
+	after := "2.3.4-new"
+	cluster := &sous.Cluster{BaseURL: "cluster", AutoRollback: true}
+	pair := &sous.DeploymentPair{
+		Prior: &sous.Deployment{
+			SourceID:     sous.MustNewSourceID("reqid", "", before),
+			DeployConfig: sous.DeployConfig{NumInstances: 1},
+			ClusterName:  "cluster",
+			Cluster:      cluster,
+		},
+		Post: &sous.Deployment{
+			SourceID:     sous.MustNewSourceID("reqid", "", after),
+			DeployConfig: sous.DeployConfig{NumInstances: 2},
+			ClusterName:  "cluster",
+			Cluster:      cluster,
+		},
+	}
+
+	mods := make(chan *sous.DeploymentPair, 1)
+	errs := make(chan sous.RectificationError, 1)
+
+	nc := sous.NewDummyRegistry()
+	client := &failingDeployClient{sous.NewDummyRectificationClient(nc), 1}
+
+	deployer := NewDeployer(nc, client)
+
+	mods <- pair
+	close(mods)
+	deployer.RectifyModifies(mods, errs)
+	close(errs)
+
+	var es []sous.RectificationError
+	for e := range errs {
+		es = append(es, e)
+	}
+	if assert.Len(es, 1) {
+		rbe, ok := es[0].(*sous.RollbackError)
+		if assert.True(ok) {
+			assert.IsType(&DeployFailed{}, rbe.Err)
+			assert.NoError(rbe.RollbackErr)
+		}
+	}
+
+	if assert.Len(client.Deployed, 2) {
+		assert.Regexp(after, client.Deployed[0].ImageName)
+		assert.Regexp(before, client.Deployed[1].ImageName)
+	}
+	if assert.Len(client.Created, 2) {
+		assert.Equal(2, client.Created[0].Count)
+		assert.Equal(1, client.Created[1].Count)
+	}
+}
+
 func TestModifyResources(t *testing.T) {
 	assert := assert.New(t)
 	version := "1.2.3-test"
@@ -292,15 +496,10 @@ func TestDeletes(t *testing.T) {
 	assert.Len(client.Deployed, 0)
 	assert.Len(client.Created, 0)
 
-	// We no longer expect any deletions; See deployer.RectifySingleDelete.
-	//expectedDeletions := 1
-	expectedDeletions := 0
-
-	if assert.Len(client.Deleted, expectedDeletions) {
-		// We no longer expect any deletions; See deployer.RectifySingleDelete.
-		//req := client.Deleted[0]
-		//assert.Equal("cluster", req.Cluster)
-		//assert.Equal("reqid::", req.Reqid)
+	if assert.Len(client.Deleted, 1) {
+		req := client.Deleted[0]
+		assert.Equal("cluster", req.Cluster)
+		assert.Equal("reqid::", req.Reqid)
 	}
 }
 
@@ -349,3 +548,23 @@ func TestCreates(t *testing.T) {
 		assert.Equal(12, req.Count)
 	}
 }
+
+func TestRateLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	limited := &sous.Cluster{BaseURL: "limited", RateLimit: 100}
+	unlimited := &sous.Cluster{BaseURL: "unlimited"}
+	rl := newRateLimits()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		rl.wait(unlimited)
+	}
+	assert.True(time.Since(start) < 10*time.Millisecond)
+
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		rl.wait(limited)
+	}
+	assert.True(time.Since(start) >= 40*time.Millisecond)
+}
@@ -76,7 +76,7 @@ func (dsm *DiskStateManager) ReadState() (*sous.State, error) {
 		m, _ := s.Manifests.Get(k)
 		for clusterName := range m.Deployments {
 			_, ok := s.Defs.Clusters[clusterName]
-			if clusterName != "Global" && !ok {
+			if clusterName != sous.GlobalDeploySpec && !ok {
 				return s, errors.Errorf("cluster %q not defined (from manifest %q)",
 					clusterName, k)
 			}
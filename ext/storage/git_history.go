@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+
+	"github.com/opentable/sous/lib"
+	"github.com/opentable/sous/util/yaml"
+	"github.com/pkg/errors"
+)
+
+// gitLogFormat separates the fields of each commit with a unit separator,
+// which can't appear in them.
+const gitLogFormat = "--format=%H%x1f%an%x1f%at%x1f%s"
+
+// ManifestHistory implements sous.ManifestHistorian on GitStateManager. It
+// lists the commits which touched the manifest's file, most recent first.
+func (gsm *GitStateManager) ManifestHistory(mid sous.ManifestID) ([]sous.ManifestChange, error) {
+	if !gsm.isRepo() {
+		return nil, errors.Errorf("state in %s is not kept in git", gsm.DiskStateManager.BaseDir)
+	}
+	path := mid.FileLocation()
+	out, err := gsm.gitOutput("log", gitLogFormat, "--", path)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []sous.ManifestChange
+	for _, line := range bytes.Split(bytes.TrimSpace(out), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		fields := bytes.SplitN(line, []byte("\x1f"), 4)
+		if len(fields) != 4 {
+			return nil, errors.Errorf("unexpected git log output: %q", line)
+		}
+		secs, err := strconv.ParseInt(string(fields[2]), 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing time of commit %s", fields[0])
+		}
+		c := sous.ManifestChange{
+			Revision: string(fields[0]),
+			Author:   string(fields[1]),
+			Time:     time.Unix(secs, 0).UTC(),
+			Message:  string(fields[3]),
+		}
+		if c.Manifest, err = gsm.manifestAt(c.Revision, path); err != nil {
+			return nil, err
+		}
+		versions = append(versions, c)
+	}
+	return sous.ManifestChanges(versions), nil
+}
+
+// manifestAt returns the manifest in the file at path as of revision, or
+// nil if there was no such file.
+func (gsm *GitStateManager) manifestAt(revision, path string) (*sous.Manifest, error) {
+	if _, err := gsm.gitOutput("cat-file", "-e", revision+":"+path); err != nil {
+		return nil, nil
+	}
+	b, err := gsm.gitOutput("show", revision+":"+path)
+	if err != nil {
+		return nil, err
+	}
+	m := &sous.Manifest{}
+	if err := yaml.Unmarshal(b, m); err != nil {
+		return nil, errors.Wrapf(err, "reading %s at %s", path, revision)
+	}
+	return m, nil
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/lib"
+)
+
+func TestGitManifestHistory(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	gsm, _ := setupManagers(t)
+
+	mid := sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/opentable/sous"}}
+	state, err := gsm.ReadState()
+	require.NoError(err)
+	m, ok := state.Manifests.Get(mid)
+	require.True(ok)
+	m.Owners = append(m.Owners, "Ursula")
+	state.Manifests.Set(mid, m)
+	require.NoError(gsm.WriteState(state))
+
+	changes, err := gsm.ManifestHistory(mid)
+	require.NoError(err)
+	require.Len(changes, 2)
+
+	assert.Equal("sous commit: Update State", changes[0].Message)
+	assert.Equal("Sous", changes[0].Author)
+	assert.Len(changes[0].Revision, 40)
+	require.NotNil(changes[0].Manifest)
+	assert.Equal([]string{"Judson", "Sam", "Ursula"}, changes[0].Manifest.Owners)
+	assert.Equal([]string{"number of owners; this: 2; other: 3"}, changes[0].Diffs)
+	assert.Equal([]string{"created"}, changes[1].Diffs)
+
+	changes, err = gsm.ManifestHistory(sous.ManifestID{Source: sous.SourceLocation{Repo: "github.com/opentable/nope"}})
+	assert.NoError(err)
+	assert.Len(changes, 0)
+}
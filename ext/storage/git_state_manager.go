@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"bytes"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -47,6 +48,20 @@ func (gsm *GitStateManager) git(cmd ...string) error {
 	return errors.Wrapf(err, strings.Join(git.Args, " ")+": "+string(out))
 }
 
+// gitOutput runs a git command in the state directory, and returns what it
+// writes to stdout.
+func (gsm *GitStateManager) gitOutput(cmd ...string) ([]byte, error) {
+	git := exec.Command(`git`, cmd...)
+	git.Dir = gsm.DiskStateManager.BaseDir
+	stderr := &bytes.Buffer{}
+	git.Stderr = stderr
+	out, err := git.Output()
+	if err != nil {
+		sous.Log.Debug.Printf("%+v: error: %v", git.Args, err)
+	}
+	return out, errors.Wrapf(err, "%s: %s", strings.Join(git.Args, " "), stderr.String())
+}
+
 func (gsm *GitStateManager) revert(tn string) {
 	gsm.git("reset", "--hard", tn)
 	gsm.git("clean", "-f")
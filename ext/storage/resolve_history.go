@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	// triggers the loading of sqlite3 as a database driver
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+type (
+	// ResolveHistoryDB implements sous.ResolveHistory using a SQL database,
+	// usually SQLite.
+	ResolveHistoryDB struct {
+		DB *sql.DB
+	}
+
+	// A ResolveHistoryFile is a ResolveHistoryDB kept in a SQLite file. The
+	// file is only opened when it's first used, and only created when a
+	// resolve is first recorded.
+	ResolveHistoryFile struct {
+		path string
+		sync.Mutex
+		db *ResolveHistoryDB
+	}
+)
+
+const resolveHistorySchema = "create table if not exists resolve_history(" +
+	"resolve_id integer primary key autoincrement, " +
+	"started text not null, " +
+	"finished text not null, " +
+	"filter text not null, " +
+	"diffs text not null, " +
+	"errors text not null, " +
+	"blocked text not null, " +
+	"freeze_override text not null" +
+	");"
+
+// NewResolveHistoryDB returns a ResolveHistoryDB backed by db, creating its
+// table if need be.
+func NewResolveHistoryDB(db *sql.DB) (*ResolveHistoryDB, error) {
+	if _, err := db.Exec(resolveHistorySchema); err != nil {
+		return nil, errors.Wrap(err, "creating resolve history table")
+	}
+	return &ResolveHistoryDB{DB: db}, nil
+}
+
+// NewResolveHistoryFile returns a ResolveHistoryFile for the SQLite file at
+// path.
+func NewResolveHistoryFile(path string) *ResolveHistoryFile {
+	return &ResolveHistoryFile{path: path}
+}
+
+// open returns the database in the file, opening it if need be. If create is
+// false, and there is no file yet, it returns nil.
+func (f *ResolveHistoryFile) open(create bool) (*ResolveHistoryDB, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.db != nil {
+		return f.db, nil
+	}
+	if _, err := os.Stat(f.path); !create && os.IsNotExist(err) {
+		return nil, nil
+	}
+	db, err := sql.Open("sqlite3", f.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening resolve history")
+	}
+	h, err := NewResolveHistoryDB(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	f.db = h
+	return h, nil
+}
+
+// RecordResolve implements sous.ResolveHistory
+func (f *ResolveHistoryFile) RecordResolve(rec *sous.ResolveRecord) error {
+	h, err := f.open(true)
+	if err != nil {
+		return err
+	}
+	return h.RecordResolve(rec)
+}
+
+// ResolveRecords implements sous.ResolveHistory. There are none until a
+// resolve is recorded.
+func (f *ResolveHistoryFile) ResolveRecords(limit int) ([]*sous.ResolveRecord, error) {
+	h, err := f.open(false)
+	if err != nil || h == nil {
+		return []*sous.ResolveRecord{}, err
+	}
+	return h.ResolveRecords(limit)
+}
+
+// Close closes the file, if it was opened.
+func (f *ResolveHistoryFile) Close() error {
+	f.Lock()
+	defer f.Unlock()
+	if f.db == nil {
+		return nil
+	}
+	err := f.db.DB.Close()
+	f.db = nil
+	return err
+}
+
+// RecordResolve implements sous.ResolveHistory
+func (h *ResolveHistoryDB) RecordResolve(rec *sous.ResolveRecord) error {
+	filter, err := json.Marshal(rec.Filter)
+	if err != nil {
+		return err
+	}
+	diffs, err := json.Marshal(rec.Diffs)
+	if err != nil {
+		return err
+	}
+	errs, err := json.Marshal(rec.Errors)
+	if err != nil {
+		return err
+	}
+	blocked, err := json.Marshal(rec.Blocked)
+	if err != nil {
+		return err
+	}
+	_, err = h.DB.Exec("insert into resolve_history(started, finished, filter, diffs, errors, blocked, freeze_override)"+
+		" values ($1, $2, $3, $4, $5, $6, $7);",
+		rec.Started.UTC().Format(time.RFC3339Nano), rec.Finished.UTC().Format(time.RFC3339Nano),
+		string(filter), string(diffs), string(errs), string(blocked), rec.FreezeOverride)
+	return errors.Wrap(err, "recording resolve")
+}
+
+// ResolveRecords implements sous.ResolveHistory
+func (h *ResolveHistoryDB) ResolveRecords(limit int) ([]*sous.ResolveRecord, error) {
+	rows, err := h.DB.Query("select started, finished, filter, diffs, errors, blocked, freeze_override from resolve_history"+
+		" order by resolve_id desc limit $1;", limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading resolve history")
+	}
+	defer rows.Close()
+
+	recs := []*sous.ResolveRecord{}
+	for rows.Next() {
+		var started, finished, filter, diffs, errs, blocked string
+		rec := &sous.ResolveRecord{}
+		if err := rows.Scan(&started, &finished, &filter, &diffs, &errs, &blocked, &rec.FreezeOverride); err != nil {
+			return nil, errors.Wrap(err, "reading resolve history")
+		}
+		if rec.Started, err = time.Parse(time.RFC3339Nano, started); err != nil {
+			return nil, err
+		}
+		if rec.Finished, err = time.Parse(time.RFC3339Nano, finished); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(filter), &rec.Filter); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(diffs), &rec.Diffs); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(errs), &rec.Errors); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(blocked), &rec.Blocked); err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, errors.Wrap(rows.Err(), "reading resolve history")
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/lib"
+)
+
+func TestResolveHistoryDB(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	db, err := sql.Open("sqlite3", "file:resolvehistory?mode=memory&cache=shared")
+	require.NoError(err)
+	defer db.Close()
+	h, err := NewResolveHistoryDB(db)
+	require.NoError(err)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(h.RecordResolve(&sous.ResolveRecord{
+			Started:  start.Add(time.Duration(i) * time.Minute),
+			Finished: start.Add(time.Duration(i)*time.Minute + time.Second),
+			Filter:   sous.ResolveFilter{Cluster: "left"},
+			Diffs: []sous.ResolveDiff{
+				{Kind: "modify", Cluster: "left", ManifestID: "github.com/ot/one", Version: "1.0.1", Changes: []string{"version"}},
+			},
+			Errors:         []string{"it broke"},
+			Blocked:        []sous.ResolveDiff{{Kind: "create", Cluster: "right", ManifestID: "github.com/ot/two", Reason: "holidays"}},
+			FreezeOverride: "jdoe",
+		}))
+	}
+
+	recs, err := h.ResolveRecords(2)
+	require.NoError(err)
+	require.Len(recs, 2)
+	assert.True(recs[0].Started.Equal(start.Add(2 * time.Minute)))
+	assert.True(recs[1].Started.Equal(start.Add(time.Minute)))
+	assert.Equal("left", recs[0].Filter.Cluster)
+	if assert.Len(recs[0].Diffs, 1) {
+		assert.Equal("1.0.1", recs[0].Diffs[0].Version)
+		assert.Equal([]string{"version"}, recs[0].Diffs[0].Changes)
+	}
+	assert.Equal([]string{"it broke"}, recs[0].Errors)
+	if assert.Len(recs[0].Blocked, 1) {
+		assert.Equal("holidays", recs[0].Blocked[0].Reason)
+	}
+	assert.Equal("jdoe", recs[0].FreezeOverride)
+}
+
+func TestResolveHistoryFileIsLazy(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "sous-resolve-history")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "history.db")
+
+	f := NewResolveHistoryFile(path)
+	recs, err := f.ResolveRecords(10)
+	require.NoError(err)
+	assert.Len(recs, 0)
+	_, err = os.Stat(path)
+	assert.True(os.IsNotExist(err), "reading the history created its file")
+
+	require.NoError(f.RecordResolve(&sous.ResolveRecord{Started: time.Now(), Finished: time.Now()}))
+	recs, err = f.ResolveRecords(10)
+	require.NoError(err)
+	assert.Len(recs, 1)
+	require.NoError(f.Close())
+
+	// It can be reopened after it's closed.
+	recs, err = f.ResolveRecords(10)
+	require.NoError(err)
+	assert.Len(recs, 1)
+	require.NoError(f.Close())
+}
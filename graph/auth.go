@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/opentable/sous/config"
+	"github.com/pkg/errors"
+)
+
+// bearerTransport adds a bearer token to each request it sends.
+type bearerTransport struct {
+	token string
+	next  http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper on bearerTransport.
+func (bt *bearerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the request they're given.
+	rq := *r
+	rq.Header = http.Header{}
+	for k, v := range r.Header {
+		rq.Header[k] = v
+	}
+	rq.Header.Set("Authorization", "Bearer "+bt.token)
+	return bt.next.RoundTrip(&rq)
+}
+
+// newServerTransport returns the transport used to talk to the Sous server,
+// which authenticates as cfg describes. If cfg describes no credentials, it
+// returns nil, so that http.DefaultTransport is used.
+func newServerTransport(cfg *config.Config) (http.RoundTripper, error) {
+	if cfg.AuthToken == "" && cfg.ClientCert == "" {
+		return nil, nil
+	}
+	var next http.RoundTripper = http.DefaultTransport
+	if cfg.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading client certificate")
+		}
+		next = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		}
+	}
+	if cfg.AuthToken == "" {
+		return next, nil
+	}
+	return &bearerTransport{token: cfg.AuthToken, next: next}, nil
+}
@@ -7,11 +7,17 @@ import (
 	"log"
 	"os"
 	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/opentable/sous/config"
 	"github.com/opentable/sous/ext/docker"
 	"github.com/opentable/sous/ext/git"
 	"github.com/opentable/sous/ext/github"
+	"github.com/opentable/sous/ext/kubernetes"
+	"github.com/opentable/sous/ext/secrets"
 	"github.com/opentable/sous/ext/singularity"
 	"github.com/opentable/sous/ext/storage"
 	"github.com/opentable/sous/lib"
@@ -89,6 +95,9 @@ type (
 	TargetManifestID sous.ManifestID
 	// Dryrun option
 	DryrunOption string
+	// DeployTimeout is how long to wait for each Singularity deploy to
+	// succeed or fail. If it is zero, deploys aren't waited for.
+	DeployTimeout time.Duration
 )
 
 const (
@@ -169,7 +178,8 @@ func AddDocker(graph adder) {
 	)
 }
 
-// AddSingularity adds Singularity clients to the graph
+// AddSingularity adds the scheduler deployers (Singularity and Kubernetes) to
+// the graph
 func AddSingularity(graph adder) {
 	graph.Add(
 		newDeployer,
@@ -208,6 +218,7 @@ func AddInternals(graph adder) {
 		newUserSelectedOTPLDeploySpecs,
 		newTargetManifestID,
 		newResolveFilter,
+		newResolveHistory,
 		newResolver,
 		newAutoResolver,
 		newInserter,
@@ -218,8 +229,53 @@ func newResolveFilter(sf *config.DeployFilterFlags, shc sous.SourceHostChooser)
 	return sf.BuildFilter(shc.ParseSourceLocation)
 }
 
-func newResolver(filter *sous.ResolveFilter, d sous.Deployer, r sous.Registry) *sous.Resolver {
-	return sous.NewResolver(d, r, filter)
+func newResolver(filter *sous.ResolveFilter, d sous.Deployer, r sous.Registry, dryrun DryrunOption, sm *StateManager, h sous.ResolveHistory) *sous.Resolver {
+	rez := sous.NewResolver(d, r, filter)
+	// A dry run mustn't start anybody's grace period, record rollbacks, or
+	// appear in the resolve history.
+	if dryrun == DryrunNeither || dryrun == DryrunRegistry {
+		rez.Tombstones = sous.StateTombstoneKeeper{StateManager: sm.StateManager}
+		rez.Rollbacks = sous.StateRollbackKeeper{StateManager: sm.StateManager}
+		rez.History = h
+	}
+	return rez
+}
+
+// resolveHistories are the resolve history files used by graphs in this
+// process, by path. They are shared because the server builds a graph for
+// every request.
+var resolveHistories = struct {
+	sync.Mutex
+	files map[string]*storage.ResolveHistoryFile
+}{files: map[string]*storage.ResolveHistoryFile{}}
+
+func newResolveHistory(c LocalSousConfig) sous.ResolveHistory {
+	resolveHistories.Lock()
+	defer resolveHistories.Unlock()
+	f, ok := resolveHistories.files[c.ResolveHistory]
+	if !ok {
+		f = storage.NewResolveHistoryFile(c.ResolveHistory)
+		resolveHistories.files[c.ResolveHistory] = f
+	}
+	return f
+}
+
+// CloseResolveHistories closes the resolve history files opened by graphs in
+// this process. It should be called before the process exits.
+func CloseResolveHistories() error {
+	resolveHistories.Lock()
+	defer resolveHistories.Unlock()
+	var errs []string
+	for path, f := range resolveHistories.files {
+		if err := f.Close(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", path, err))
+		}
+		delete(resolveHistories.files, path)
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("closing resolve histories: %s", strings.Join(errs, "; "))
+	}
+	return nil
 }
 
 func newAutoResolver(rez *sous.Resolver, sr LocalStateReader, ls *sous.LogSet) *sous.AutoResolver {
@@ -394,26 +450,47 @@ func newRegistry(dryrun DryrunOption, cfg LocalSousConfig, cl LocalDockerClient)
 	return makeDockerRegistry(cfg, cl)
 }
 
-func newDeployer(dryrun DryrunOption, r sous.Registry) sous.Deployer {
-	// Eventually, based on configuration, we may make different decisions here.
+// ConfiguredDeployTimeout is the DeployTimeout set in the Sous configuration,
+// for commands which have no flag to set it.
+func ConfiguredDeployTimeout(c LocalSousConfig) DeployTimeout {
+	return DeployTimeout(time.Duration(c.Singularity.DeployTimeout) * time.Second)
+}
+
+func newDeployer(dryrun DryrunOption, timeout DeployTimeout, r sous.Registry, c LocalSousConfig) sous.Deployer {
+	mux := sous.NewDeployerMux()
 	if dryrun == DryrunBoth || dryrun == DryrunScheduler {
+		logger := log.New(os.Stdout, "rectify: ", 0)
 		drc := sous.NewDummyRectificationClient(r)
-		drc.SetLogger(log.New(os.Stdout, "rectify: ", 0))
-		return singularity.NewDeployer(r, drc)
+		drc.SetLogger(logger)
+		mux.Register("singularity", singularity.NewDeployer(r, drc))
+		mux.Register("kubernetes", kubernetes.NewDeployer(r, kubernetes.NewDryrunAgent(logger)))
+		return mux
 	}
-	return singularity.NewDeployer(r, singularity.NewRectiAgent(r))
+	ra := singularity.NewRectiAgent(r)
+	ra.DeployTimeout = time.Duration(timeout)
+	if c.SecretsDir != "" {
+		ra.Secrets = secrets.NewFileProvider(c.SecretsDir)
+	}
+	mux.Register("singularity", singularity.NewDeployer(r, ra))
+	mux.Register("kubernetes", kubernetes.NewDeployer(r, kubernetes.NewKubeAgent()))
+	return mux
 }
 
 func newDockerClient() LocalDockerClient {
 	return LocalDockerClient{docker_registry.NewClient()}
 }
 
-func newStateManager(c LocalSousConfig) (*StateManager, error) {
+func newStateManager(c LocalSousConfig, u LocalUser) (*StateManager, error) {
 	if c.Server != "" {
 		hsm, err := sous.NewHTTPStateManager(c.Server)
 		if err != nil {
 			return nil, err
 		}
+		hsm.CacheDir = filepath.Join(u.ConfigDir(), "cache")
+		hsm.Transport, err = newServerTransport(c.Config)
+		if err != nil {
+			return nil, err
+		}
 		return &StateManager{StateManager: hsm}, nil
 	}
 	dm := storage.NewDiskStateManager(c.StateLocation)
@@ -463,7 +540,12 @@ func newInserter(cfg LocalSousConfig, cl LocalDockerClient) (sous.Inserter, erro
 	if cfg.Server == "" {
 		return makeDockerRegistry(cfg, cl)
 	}
-	return sous.NewHTTPNameInserter(cfg.Server)
+	hni, err := sous.NewHTTPNameInserter(cfg.Server)
+	if err != nil {
+		return nil, err
+	}
+	hni.Transport, err = newServerTransport(cfg.Config)
+	return hni, err
 }
 
 // initErr returns nil if error is nil, otherwise an initialisation error.
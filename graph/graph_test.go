@@ -3,6 +3,7 @@ package graph
 import (
 	"io/ioutil"
 	"log"
+	"os/user"
 	"testing"
 
 	"github.com/opentable/sous/config"
@@ -17,6 +18,7 @@ func TestBuildGraph(t *testing.T) {
 	log.SetFlags(log.Flags() | log.Lshortfile)
 	g := BuildGraph(ioutil.Discard, ioutil.Discard)
 	g.Add(DryrunBoth)
+	g.Add(ConfiguredDeployTimeout)
 	g.Add(&config.Verbosity{})
 	g.Add(&config.DeployFilterFlags{})
 	g.Add(&config.PolicyFlags{}) //provided by SousBuild
@@ -31,6 +33,7 @@ func injectedStateManager(t *testing.T, cfg *config.Config) *StateManager {
 	g := psyringe.New()
 	g.Add(newStateManager)
 	g.Add(LocalSousConfig{Config: cfg})
+	g.Add(LocalUser{&config.User{User: &user.User{HomeDir: "/tmp/sous"}}})
 
 	smRcvr := struct {
 		Sm *StateManager
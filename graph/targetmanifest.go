@@ -59,7 +59,7 @@ func newTargetManifest(auto UserSelectedOTPLDeploySpecs, tmid TargetManifestID,
 
 func defaultDeploySpecs() sous.DeploySpecs {
 	return sous.DeploySpecs{
-		"Global": {
+		sous.GlobalDeploySpec: {
 			DeployConfig: sous.DeployConfig{
 				Resources:    sous.Resources{},
 				Env:          map[string]string{},
@@ -244,7 +244,8 @@ func TestResolve(t *testing.T) {
 		}
 	}
 
-	// We no longer expect any deletions; See deployer.RectifySingleDelete.
+	// test-cluster has no DeletePolicy, so nothing is deleted; see
+	// sous.Resolver.guardDeletes.
 	//expectedInstances := 0
 	expectedInstances := 1
 
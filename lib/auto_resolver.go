@@ -1,6 +1,7 @@
 package sous
 
 import (
+	"sync"
 	"time"
 )
 
@@ -20,6 +21,28 @@ type (
 		*Resolver
 		*LogSet
 		listeners []AutoResolveListener
+		// poke cuts short the wait for the next resolve.
+		poke       triggerChannel
+		status     AutoResolveStatus
+		statusLock sync.Mutex
+	}
+
+	// An AutoResolveStatus reports on an AutoResolver's resolve cycles.
+	AutoResolveStatus struct {
+		// Filter limits the clusters and deployments that are resolved.
+		Filter ResolveFilter
+		// Running is true while a resolve is in progress.
+		Running bool
+		// Started is when the latest resolve started.
+		Started time.Time
+		// Finished is when the latest completed resolve finished.
+		Finished time.Time
+		// Errors are the errors from the latest completed resolve: each cause
+		// of its ResolveErrors, or the single error that stopped it.
+		Errors []string
+		// Next is when the next resolve will start. It is zero while a resolve
+		// is running, since the next isn't scheduled until that finishes.
+		Next time.Time
 	}
 )
 
@@ -35,6 +58,10 @@ func NewAutoResolver(rez *Resolver, sr StateReader, ls *LogSet) *AutoResolver {
 		StateReader: sr,
 		LogSet:      ls,
 		listeners:   make([]AutoResolveListener, 0),
+		poke:        make(triggerChannel, 1),
+	}
+	if rez != nil && rez.ResolveFilter != nil {
+		ar.status.Filter = *rez.ResolveFilter
 	}
 	ar.StandardListeners()
 	return ar
@@ -104,23 +131,11 @@ func (ar *AutoResolver) resolveLoop(tc, done triggerChannel, ac announceChannel)
 	for {
 		select {
 		default:
-			ar.LogSet.Debug.Print("Beginning Resolve")
-			state, err := ar.StateReader.ReadState()
-			ar.LogSet.Debug.Printf("Reading current state: err: %v", err)
-			if err != nil {
-				ac <- err
-				break
-			}
-			gdm, err := state.Deployments()
-			ar.LogSet.Debug.Printf("Reading GDM from state: err: %v", err)
-
-			if err != nil {
-				ac <- err
-				break
-			}
-
-			ac <- ar.Resolver.Resolve(gdm, state.Defs.Clusters)
-			ar.LogSet.Debug.Print("Completed resolve")
+			start := ar.started()
+			err := ar.resolveOnce()
+			observeResolve(start, err)
+			ar.finished(err)
+			ac <- err
 		case <-done:
 			return
 		case t := <-tc:
@@ -132,16 +147,85 @@ func (ar *AutoResolver) resolveLoop(tc, done triggerChannel, ac announceChannel)
 	}
 }
 
+// Trigger starts a resolve now, rather than at the next scheduled time. If a
+// resolve is already running, the next one starts as soon as it finishes.
+func (ar *AutoResolver) Trigger() {
+	select {
+	case ar.poke <- triggerType{}:
+	default:
+		// A resolve is already due.
+	}
+}
+
+// Status reports on the latest resolve, and when the next will start.
+func (ar *AutoResolver) Status() AutoResolveStatus {
+	ar.statusLock.Lock()
+	defer ar.statusLock.Unlock()
+	status := ar.status
+	status.Errors = append([]string(nil), ar.status.Errors...)
+	return status
+}
+
+func (ar *AutoResolver) started() time.Time {
+	ar.statusLock.Lock()
+	defer ar.statusLock.Unlock()
+	ar.status.Running = true
+	ar.status.Started = time.Now()
+	ar.status.Next = time.Time{}
+	return ar.status.Started
+}
+
+func (ar *AutoResolver) finished(err error) {
+	ar.statusLock.Lock()
+	defer ar.statusLock.Unlock()
+	ar.status.Running = false
+	ar.status.Finished = time.Now()
+	ar.status.Errors = nil
+	if re, is := err.(*ResolveErrors); is {
+		for _, e := range re.Causes {
+			ar.status.Errors = append(ar.status.Errors, e.Error())
+		}
+	} else if err != nil {
+		ar.status.Errors = []string{err.Error()}
+	}
+}
+
+func (ar *AutoResolver) scheduled(next time.Time) {
+	ar.statusLock.Lock()
+	defer ar.statusLock.Unlock()
+	ar.status.Next = next
+}
+
+func (ar *AutoResolver) resolveOnce() error {
+	ar.LogSet.Debug.Print("Beginning Resolve")
+	state, err := ar.StateReader.ReadState()
+	ar.LogSet.Debug.Printf("Reading current state: err: %v", err)
+	if err != nil {
+		return err
+	}
+	gdm, err := state.Deployments()
+	ar.LogSet.Debug.Printf("Reading GDM from state: err: %v", err)
+	if err != nil {
+		return err
+	}
+
+	err = ar.Resolver.Resolve(gdm, state.Defs.Clusters)
+	ar.LogSet.Debug.Print("Completed resolve")
+	return err
+}
+
 func (ar *AutoResolver) afterDone(tc, done triggerChannel, ac announceChannel) {
 	select {
 	case <-done:
 		return
 	case <-ac:
 	}
+	ar.scheduled(time.Now().Add(ar.UpdateTime))
 	select {
 	case <-done:
 		return
 	case <-time.After(ar.UpdateTime):
+	case <-ar.poke:
 	}
 	tc.trigger()
 }
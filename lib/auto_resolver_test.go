@@ -1,6 +1,7 @@
 package sous
 
 import (
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -8,6 +9,14 @@ import (
 	"github.com/nyarly/testify/assert"
 )
 
+type failingDeployer struct {
+	*DummyDeployer
+}
+
+func (failingDeployer) RunningDeployments(Clusters) (Deployments, error) {
+	return NewDeployments(), errors.New("running deployments failed")
+}
+
 func dummyResolver() *Resolver {
 	return NewResolver(NewDummyDeployer(), NewDummyRegistry(), &ResolveFilter{})
 }
@@ -83,3 +92,58 @@ func TestResolveLoop(t *testing.T) {
 		t.Error("Should have announced a result")
 	}
 }
+
+func TestResolveLoopStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	rez := NewResolver(failingDeployer{NewDummyDeployer()}, NewDummyRegistry(), &ResolveFilter{Cluster: "owned"})
+	ar := NewAutoResolver(rez, &DummyStateManager{State: NewState()}, SilentLogSet())
+	assert.Equal("owned", ar.Status().Filter.Cluster)
+
+	tc := make(triggerChannel, 1)
+	ac := make(announceChannel, 1)
+	done := make(triggerChannel)
+
+	tc.trigger()
+	ar.resolveLoop(tc, done, ac)
+	<-ac
+
+	status := ar.Status()
+	assert.False(status.Running)
+	assert.False(status.Finished.Before(status.Started))
+	assert.Equal([]string{"running deployments failed"}, status.Errors)
+	assert.True(status.Next.IsZero())
+}
+
+func TestTrigger(t *testing.T) {
+	assert := assert.New(t)
+
+	ar := setupAR()
+	ar.UpdateTime = time.Hour
+
+	tc := make(triggerChannel, 1)
+	ac := make(announceChannel, 1)
+	done := make(triggerChannel)
+
+	// A second trigger, while one is already due, is dropped.
+	ar.Trigger()
+	ar.Trigger()
+
+	ac <- nil
+	ar.afterDone(tc, done, ac)
+	select {
+	case <-tc:
+	default:
+		t.Error("Trigger channel not triggered")
+	}
+	assert.WithinDuration(time.Now().Add(time.Hour), ar.Status().Next, time.Minute)
+
+	ac <- nil
+	go ar.afterDone(tc, done, ac)
+	select {
+	case <-tc:
+		t.Error("Triggered without waiting")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(done)
+}
@@ -0,0 +1,247 @@
+package sous
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// DeletePolicy describes what Sous does with deployments that are running
+	// in a cluster, but no longer appear in the GDM.
+	DeletePolicy struct {
+		// Mode is one of "never" (the default), "grace" or "immediate".
+		Mode DeleteMode
+		// GracePeriod is how long a deployment must have been missing from the
+		// GDM before it is deleted, in time.ParseDuration format, e.g. "72h".
+		// It is only used when Mode is "grace".
+		GracePeriod string
+	}
+
+	// DeleteMode selects how a DeletePolicy behaves.
+	DeleteMode string
+
+	// A Tombstone records that a deployment was found running without a
+	// manifest, and is waiting out its cluster's grace period before deletion.
+	Tombstone struct {
+		// Deployment identifies the deployment awaiting deletion.
+		Deployment DeployID
+		// Since is when the deployment was first found missing from the GDM.
+		Since time.Time
+	}
+
+	// Tombstones is a collection of Tombstone.
+	Tombstones []*Tombstone
+
+	// A TombstoneKeeper stores tombstones between resolutions.
+	TombstoneKeeper interface {
+		ReadTombstones() (Tombstones, error)
+		WriteTombstones(Tombstones) error
+	}
+
+	// StateTombstoneKeeper keeps tombstones in State.Tombstones.
+	StateTombstoneKeeper struct {
+		StateManager
+	}
+)
+
+const (
+	// DeleteNever means that Sous never deletes deployments, and only warns
+	// about them.
+	DeleteNever DeleteMode = "never"
+	// DeleteAfterGrace means that Sous deletes deployments once they have been
+	// missing from the GDM for the policy's GracePeriod.
+	DeleteAfterGrace DeleteMode = "grace"
+	// DeleteImmediately means that Sous deletes deployments as soon as they
+	// are missing from the GDM.
+	DeleteImmediately DeleteMode = "immediate"
+)
+
+// Grace returns the parsed grace period of this policy.
+func (p DeletePolicy) Grace() (time.Duration, error) {
+	d, err := time.ParseDuration(p.GracePeriod)
+	return d, errors.Wrapf(err, "delete policy grace period")
+}
+
+// Validate checks that this policy's mode is recognised, and that a grace
+// period is given for the grace mode.
+func (p DeletePolicy) Validate() []Flaw {
+	switch p.Mode {
+	default:
+		return []Flaw{unrepairableFlaw("delete policy mode %q not valid", p.Mode)}
+	case "", DeleteNever, DeleteImmediately:
+		return nil
+	case DeleteAfterGrace:
+		if g, err := p.Grace(); err != nil || g < 0 {
+			return []Flaw{unrepairableFlaw("delete policy grace period %q not valid", p.GracePeriod)}
+		}
+		return nil
+	}
+}
+
+// Get returns the tombstone for a deployment, if there is one.
+func (ts Tombstones) Get(id DeployID) (*Tombstone, bool) {
+	for _, t := range ts {
+		if t.Deployment == id {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Expiry returns the time after which the tombstoned deployment will be
+// deleted, according to the delete policy of its cluster. It returns false if
+// the cluster's policy does not have a grace period.
+func (t *Tombstone) Expiry(clusters Clusters) (time.Time, bool) {
+	c, ok := clusters[t.Deployment.Cluster]
+	if !ok || c == nil || c.DeletePolicy.Mode != DeleteAfterGrace {
+		return time.Time{}, false
+	}
+	grace, err := c.DeletePolicy.Grace()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.Since.Add(grace), true
+}
+
+// Clone returns a deep copy of this Tombstones.
+func (ts Tombstones) Clone() Tombstones {
+	if ts == nil {
+		return nil
+	}
+	c := make(Tombstones, 0, len(ts))
+	for _, t := range ts {
+		tc := *t
+		c = append(c, &tc)
+	}
+	return c
+}
+
+// ReadTombstones implements TombstoneKeeper
+func (k StateTombstoneKeeper) ReadTombstones() (Tombstones, error) {
+	s, err := k.ReadState()
+	if err != nil {
+		return nil, err
+	}
+	return s.Tombstones, nil
+}
+
+// WriteTombstones implements TombstoneKeeper
+func (k StateTombstoneKeeper) WriteTombstones(ts Tombstones) error {
+	s, err := k.ReadState()
+	if err != nil {
+		return err
+	}
+	s.Tombstones = ts
+	return k.WriteState(s)
+}
+
+// guardDeletes applies the delete policies of clusters to a stream of
+// deployments to delete, and passes on only those that should really be
+// deleted now. Tombstones are created for deployments entering their grace
+// period, and cleared by the first resolve which finds them no longer running,
+// or back in the intended deployments. The returned error channel receives
+// exactly one value once the input is exhausted.
+func (r *Resolver) guardDeletes(in <-chan *Deployment, intended Deployments, clusters Clusters) (chan *Deployment, chan error) {
+	out := make(chan *Deployment, cap(in))
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(out)
+
+		var old Tombstones
+		if r.Tombstones != nil {
+			var err error
+			if old, err = r.Tombstones.ReadTombstones(); err != nil {
+				for range in {
+				}
+				errs <- errors.Wrap(err, "reading tombstones")
+				return
+			}
+		}
+
+		now := time.Now()
+		seen := make(map[DeployID]struct{})
+		var kept Tombstones
+		for d := range in {
+			id := d.ID()
+			seen[id] = struct{}{}
+			var policy DeletePolicy
+			if c, ok := clusters[d.ClusterName]; ok && c != nil {
+				policy = c.DeletePolicy
+			}
+
+			switch policy.Mode {
+			default:
+				Log.Warn.Printf("NOT DELETING %q in %q: the cluster never deletes deployments", id.ManifestID, id.Cluster)
+			case DeleteImmediately:
+				out <- d
+			case DeleteAfterGrace:
+				if r.Tombstones == nil {
+					Log.Warn.Printf("NOT DELETING %q in %q: no tombstones are kept to measure the grace period", id.ManifestID, id.Cluster)
+					continue
+				}
+				grace, _ := policy.Grace()
+				t, ok := old.Get(id)
+				if !ok {
+					t = &Tombstone{Deployment: id, Since: now}
+					Log.Warn.Printf("No manifest for %q in %q: deleting after %s", id.ManifestID, id.Cluster, grace)
+				}
+				// The tombstone is kept until the deployment is no longer
+				// running, so that a failed delete is tried again.
+				kept = append(kept, t)
+				if now.Sub(t.Since) >= grace {
+					out <- d
+				}
+			}
+		}
+
+		if r.Tombstones == nil {
+			errs <- nil
+			return
+		}
+
+		// Keep tombstones for deployments that this resolve didn't consider, so
+		// that a filtered resolve doesn't reset the clocks of others.
+		for _, t := range old {
+			if _, ok := seen[t.Deployment]; ok {
+				continue
+			}
+			if _, ok := intended.Get(t.Deployment); ok {
+				continue
+			}
+			if r.considers(t.Deployment, clusters) {
+				continue
+			}
+			kept = append(kept, t)
+		}
+
+		if len(kept) == 0 && len(old) == 0 {
+			errs <- nil
+			return
+		}
+		errs <- errors.Wrap(r.Tombstones.WriteTombstones(kept), "writing tombstones")
+	}()
+
+	return out, errs
+}
+
+// considers returns true if a resolve with this resolver would have
+// considered the identified deployment.
+func (r *Resolver) considers(id DeployID, clusters Clusters) bool {
+	if _, ok := clusters[id.Cluster]; !ok {
+		return false
+	}
+	rf := r.ResolveFilter
+	if rf == nil {
+		return true
+	}
+	if rf.Tag != "" || rf.Revision != "" {
+		return false
+	}
+	return rf.FilterDeployment(&Deployment{
+		SourceID:    SourceID{Location: id.ManifestID.Source},
+		Flavor:      id.ManifestID.Flavor,
+		ClusterName: id.Cluster,
+	})
+}
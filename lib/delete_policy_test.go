@@ -0,0 +1,151 @@
+package sous
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+type memTombstones struct {
+	ts     Tombstones
+	writes int
+}
+
+func (m *memTombstones) ReadTombstones() (Tombstones, error) { return m.ts.Clone(), nil }
+
+func (m *memTombstones) WriteTombstones(ts Tombstones) error {
+	m.writes++
+	m.ts = ts.Clone()
+	return nil
+}
+
+func TestDeletePolicyValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Empty(DeletePolicy{}.Validate())
+	assert.Empty(DeletePolicy{Mode: DeleteNever}.Validate())
+	assert.Empty(DeletePolicy{Mode: DeleteImmediately}.Validate())
+	assert.Empty(DeletePolicy{Mode: DeleteAfterGrace, GracePeriod: "72h"}.Validate())
+
+	assert.Len(DeletePolicy{Mode: "sometimes"}.Validate(), 1)
+	assert.Len(DeletePolicy{Mode: DeleteAfterGrace}.Validate(), 1)
+	assert.Len(DeletePolicy{Mode: DeleteAfterGrace, GracePeriod: "3 days"}.Validate(), 1)
+}
+
+func guardFixtures() Clusters {
+	return Clusters{
+		"never":     &Cluster{Name: "never"},
+		"immediate": &Cluster{Name: "immediate", DeletePolicy: DeletePolicy{Mode: DeleteImmediately}},
+		"grace":     &Cluster{Name: "grace", DeletePolicy: DeletePolicy{Mode: DeleteAfterGrace, GracePeriod: "1h"}},
+	}
+}
+
+func guardDeployment(clusters Clusters, repo, cluster string) *Deployment {
+	return &Deployment{
+		SourceID:    SourceID{Location: SourceLocation{Repo: repo}},
+		ClusterName: cluster,
+		Cluster:     clusters[cluster],
+	}
+}
+
+func runGuard(r *Resolver, intended Deployments, clusters Clusters, ds ...*Deployment) ([]DeployID, error) {
+	in := make(chan *Deployment, len(ds))
+	for _, d := range ds {
+		in <- d
+	}
+	close(in)
+	out, errs := r.guardDeletes(in, intended, clusters)
+	var ids []DeployID
+	for d := range out {
+		ids = append(ids, d.ID())
+	}
+	return ids, <-errs
+}
+
+func TestGuardDeletesByPolicy(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	clusters := guardFixtures()
+	keeper := &memTombstones{}
+	r := &Resolver{Tombstones: keeper}
+
+	never := guardDeployment(clusters, "github.com/ot/one", "never")
+	now := guardDeployment(clusters, "github.com/ot/one", "immediate")
+	later := guardDeployment(clusters, "github.com/ot/one", "grace")
+
+	ids, err := runGuard(r, NewDeployments(), clusters, never, now, later)
+	require.NoError(err)
+	assert.Equal([]DeployID{now.ID()}, ids)
+	if assert.Len(keeper.ts, 1) {
+		assert.Equal(later.ID(), keeper.ts[0].Deployment)
+	}
+
+	// Still within the grace period: the tombstone is kept as it was.
+	since := keeper.ts[0].Since
+	ids, err = runGuard(r, NewDeployments(), clusters, later)
+	require.NoError(err)
+	assert.Empty(ids)
+	if assert.Len(keeper.ts, 1) {
+		assert.Equal(since, keeper.ts[0].Since)
+	}
+
+	// Grace period over: deleted, but the tombstone is kept in case the
+	// delete fails.
+	keeper.ts[0].Since = time.Now().Add(-2 * time.Hour)
+	ids, err = runGuard(r, NewDeployments(), clusters, later)
+	require.NoError(err)
+	assert.Equal([]DeployID{later.ID()}, ids)
+	assert.Len(keeper.ts, 1)
+
+	// The delete failed: it's tried again straight away.
+	ids, err = runGuard(r, NewDeployments(), clusters, later)
+	require.NoError(err)
+	assert.Equal([]DeployID{later.ID()}, ids)
+
+	// No longer running: the tombstone is cleared.
+	ids, err = runGuard(r, NewDeployments(), clusters)
+	require.NoError(err)
+	assert.Empty(ids)
+	assert.Empty(keeper.ts)
+}
+
+func TestGuardDeletesClearsRestored(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	clusters := guardFixtures()
+	restored := guardDeployment(clusters, "github.com/ot/one", "grace")
+	other := guardDeployment(clusters, "github.com/ot/two", "grace")
+	keeper := &memTombstones{ts: Tombstones{
+		{Deployment: restored.ID(), Since: time.Now()},
+		{Deployment: other.ID(), Since: time.Now()},
+	}}
+
+	// A resolve filtered to ot/one must leave ot/two's tombstone alone.
+	r := &Resolver{Tombstones: keeper, ResolveFilter: &ResolveFilter{Repo: "github.com/ot/one"}}
+	intended := NewDeployments(restored)
+
+	ids, err := runGuard(r, intended, clusters)
+	require.NoError(err)
+	assert.Empty(ids)
+	if assert.Len(keeper.ts, 1) {
+		assert.Equal(other.ID(), keeper.ts[0].Deployment)
+	}
+}
+
+func TestGuardDeletesWithoutKeeper(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	clusters := guardFixtures()
+	r := &Resolver{}
+
+	ids, err := runGuard(r, NewDeployments(), clusters,
+		guardDeployment(clusters, "github.com/ot/one", "grace"),
+		guardDeployment(clusters, "github.com/ot/one", "immediate"))
+	require.NoError(err)
+	assert.Len(ids, 1)
+}
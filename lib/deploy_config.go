@@ -18,8 +18,13 @@ type (
 		// of this deployment. It will be checked for conflict with the
 		// definitions found in State.Defs.EnvVars, and if not in conflict
 		// assumes the greatest priority.
+		Env `yaml:",omitempty" validate:"keys=nonempty,values=nonempty"`
+		// Command, if set, replaces the default command of this deployment's
+		// image.
+		Command string `yaml:",omitempty"`
+		// Args are passed to the command, in place of any default arguments
+		// the image has.
 		Args []string `yaml:",omitempty" validate:"values=nonempty"`
-		Env  `yaml:",omitempty" validate:"keys=nonempty,values=nonempty"`
 		// NumInstances is a guide to the number of instances that should be
 		// deployed in this cluster, note that the actual number may differ due
 		// to decisions made by Sous. If set to zero, Sous will decide how many
@@ -28,6 +33,19 @@ type (
 
 		// Volumes lists the volume mappings for this deploy
 		Volumes Volumes
+
+		// Schedule is the cron schedule on which a scheduled manifest is run,
+		// e.g. "30 4 * * MON-FRI". Only scheduled manifests may have one, and
+		// they must.
+		Schedule string `yaml:",omitempty"`
+		// ScheduleTimeZone is the time zone Schedule is read in, e.g.
+		// "America/Los_Angeles". If it is not set, the scheduler's own time
+		// zone is used.
+		ScheduleTimeZone string `yaml:",omitempty"`
+
+		// HealthCheck describes how to tell if instances of this deployment are
+		// healthy.
+		HealthCheck HealthCheck `yaml:",omitempty"`
 	}
 
 	// Env is a mapping of environment variable name to value, used to provision
@@ -58,6 +76,28 @@ func (dc *DeployConfig) Validate() []Flaw {
 	}
 
 	flaws = append(flaws, rezs.Validate()...)
+	flaws = append(flaws, dc.HealthCheck.Validate(rezs)...)
+
+	for _, name := range dc.Env.names() {
+		if v := dc.Env[name]; IsSecretRef(v) {
+			if _, _, err := ParseSecretRef(v); err != nil {
+				flaws = append(flaws, unrepairableFlaw("env var %s: %s", name, err))
+			}
+		}
+	}
+
+	if dc.Schedule != "" {
+		if err := ValidateSchedule(dc.Schedule); err != nil {
+			flaws = append(flaws, unrepairableFlaw("%s", err))
+		}
+	}
+	if dc.ScheduleTimeZone != "" {
+		if dc.Schedule == "" {
+			flaws = append(flaws, unrepairableFlaw("time zone %q given without a schedule", dc.ScheduleTimeZone))
+		} else if err := ValidateTimeZone(dc.ScheduleTimeZone); err != nil {
+			flaws = append(flaws, unrepairableFlaw("%s", err))
+		}
+	}
 
 	for _, f := range flaws {
 		f.AddContext("deploy config", dc)
@@ -123,15 +163,35 @@ func (dc *DeployConfig) Diff(o DeployConfig) (bool, []string) {
 			diffs = append(diffs, fmt.Sprintf("volumes; this: %v; other: %v", dc.Volumes, o.Volumes))
 		}
 	}
-	// TODO: Compare Args
+	if dc.Schedule != o.Schedule {
+		diffs = append(diffs, fmt.Sprintf("schedule; this: %q; other: %q", dc.Schedule, o.Schedule))
+	}
+	if dc.ScheduleTimeZone != o.ScheduleTimeZone {
+		diffs = append(diffs, fmt.Sprintf("schedule time zone; this: %q; other: %q", dc.ScheduleTimeZone, o.ScheduleTimeZone))
+	}
+	if dc.Command != o.Command {
+		diffs = append(diffs, fmt.Sprintf("command; this: %q; other: %q", dc.Command, o.Command))
+	}
+	if !ArgsEqual(dc.Args, o.Args) {
+		diffs = append(diffs, fmt.Sprintf("args; this: %q; other: %q", dc.Args, o.Args))
+	}
+	if dc.HealthCheck != o.HealthCheck {
+		diffs = append(diffs, fmt.Sprintf("health check; this: %v; other: %v", dc.HealthCheck, o.HealthCheck))
+	}
 	return len(diffs) == 0, diffs
 }
 
 // Clone returns a deep copy of this DeployConfig.
 func (dc DeployConfig) Clone() (c DeployConfig) {
 	c.NumInstances = dc.NumInstances
-	c.Args = make([]string, len(dc.Args))
-	copy(dc.Args, c.Args)
+	c.Schedule = dc.Schedule
+	c.ScheduleTimeZone = dc.ScheduleTimeZone
+	c.Command = dc.Command
+	c.HealthCheck = dc.HealthCheck
+	if dc.Args != nil {
+		c.Args = make([]string, len(dc.Args))
+		copy(c.Args, dc.Args)
+	}
 	c.Env = make(Env)
 	for k, v := range dc.Env {
 		c.Env[k] = v
@@ -140,11 +200,26 @@ func (dc DeployConfig) Clone() (c DeployConfig) {
 	for k, v := range dc.Resources {
 		c.Resources[k] = v
 	}
-	c.Volumes = make(Volumes, len(dc.Volumes))
-	copy(dc.Volumes, c.Volumes)
+	if dc.Volumes != nil {
+		c.Volumes = dc.Volumes.Clone()
+	}
 	return
 }
 
+// ArgsEqual returns true if two lists of arguments are the same. A nil list
+// is the same as an empty one.
+func ArgsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // Equal compares Envs
 func (e Env) Equal(o Env) bool {
 	Log.Vomit.Printf("Envs: %+ v ?= %+ v", e, o)
@@ -180,12 +255,31 @@ func flattenDeployConfigs(dcs []DeployConfig) DeployConfig {
 			break
 		}
 	}
+	for _, c := range dcs {
+		if c.Command != "" {
+			dc.Command = c.Command
+			break
+		}
+	}
 	for _, c := range dcs {
 		if len(c.Args) != 0 {
 			dc.Args = c.Args
 			break
 		}
 	}
+	for _, c := range dcs {
+		if c.HealthCheck.Enabled() {
+			dc.HealthCheck = c.HealthCheck
+			break
+		}
+	}
+	for _, c := range dcs {
+		if c.Schedule != "" {
+			dc.Schedule = c.Schedule
+			dc.ScheduleTimeZone = c.ScheduleTimeZone
+			break
+		}
+	}
 	for _, c := range dcs {
 		for n, v := range c.Resources {
 			if _, set := dc.Resources[n]; !set {
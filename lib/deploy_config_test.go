@@ -23,3 +23,25 @@ func TestValidateRepair(t *testing.T) {
 	assert.Len(t, es, 0)
 	assert.Len(t, dc.Volumes, 1)
 }
+
+func TestDiffArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	dc := DeployConfig{Command: "/bin/app", Args: []string{"-v"}}
+	same, _ := dc.Diff(dc.Clone())
+	assert.True(same)
+	empty := DeployConfig{}
+	same, _ = empty.Diff(DeployConfig{Args: []string{}})
+	assert.True(same)
+
+	other := dc.Clone()
+	other.Args[0] = "-q"
+	assert.Equal([]string{"-v"}, dc.Args)
+	_, diffs := dc.Diff(other)
+	assert.Len(diffs, 1)
+
+	other = dc.Clone()
+	other.Command = "/bin/other"
+	_, diffs = dc.Diff(other)
+	assert.Len(diffs, 1)
+}
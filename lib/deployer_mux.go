@@ -0,0 +1,154 @@
+package sous
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DefaultClusterKind is the Kind assumed for clusters that don't declare one.
+const DefaultClusterKind = "singularity"
+
+type (
+	// DeployerMux is a Deployer that splits clusters, and the deployments
+	// bound for them, by Cluster.Kind, and hands each group to the Deployer
+	// registered for that kind. This lets a single GDM describe clusters run by
+	// different schedulers.
+	DeployerMux struct {
+		deployers map[string]Deployer
+	}
+
+	// UnknownClusterKindError is reported for deployments to clusters whose
+	// Kind has no registered Deployer.
+	UnknownClusterKindError struct {
+		Cluster, Kind string
+	}
+)
+
+func (e *UnknownClusterKindError) Error() string {
+	return fmt.Sprintf("no deployer registered for kind %q of cluster %q", e.Kind, e.Cluster)
+}
+
+// NewDeployerMux creates an empty DeployerMux.
+func NewDeployerMux() *DeployerMux {
+	return &DeployerMux{deployers: make(map[string]Deployer)}
+}
+
+// Register adds a Deployer to handle clusters of a particular kind.
+func (dm *DeployerMux) Register(kind string, d Deployer) {
+	dm.deployers[kind] = d
+}
+
+func clusterKind(c *Cluster) string {
+	if c == nil || c.Kind == "" {
+		return DefaultClusterKind
+	}
+	return c.Kind
+}
+
+func (dm *DeployerMux) deployerFor(d *Deployment) (Deployer, error) {
+	kind := clusterKind(d.Cluster)
+	dep, ok := dm.deployers[kind]
+	if !ok {
+		return nil, &UnknownClusterKindError{Cluster: d.ClusterName, Kind: kind}
+	}
+	return dep, nil
+}
+
+// RunningDeployments implements Deployer. Each registered Deployer is asked
+// about the clusters of its own kind. Clusters of unknown kinds are skipped, so
+// deployments intended for them surface as errors during rectification.
+func (dm *DeployerMux) RunningDeployments(from Clusters) (Deployments, error) {
+	byKind := make(map[string]Clusters)
+	for name, c := range from {
+		kind := clusterKind(c)
+		if _, ok := dm.deployers[kind]; !ok {
+			Log.Warn.Printf("No deployer for kind %q of cluster %q: not collecting its deployments", kind, name)
+			continue
+		}
+		if byKind[kind] == nil {
+			byKind[kind] = make(Clusters)
+		}
+		byKind[kind][name] = c
+	}
+
+	ds := NewDeployments()
+	for kind, cs := range byKind {
+		kds, err := dm.deployers[kind].RunningDeployments(cs)
+		if err != nil {
+			return ds, err
+		}
+		if conflict, ok := ds.AddAll(kds); !ok {
+			return ds, fmt.Errorf("conflicting deploys: %s", conflict)
+		}
+	}
+	return ds, nil
+}
+
+// RectifyCreates implements Deployer
+func (dm *DeployerMux) RectifyCreates(cc <-chan *Deployment, errs chan<- RectificationError) {
+	dm.routeDeployments(cc, errs,
+		func(d Deployer, c <-chan *Deployment) { d.RectifyCreates(c, errs) },
+		func(d *Deployment, err error) RectificationError { return &CreateError{Deployment: d, Err: err} })
+}
+
+// RectifyDeletes implements Deployer
+func (dm *DeployerMux) RectifyDeletes(dc <-chan *Deployment, errs chan<- RectificationError) {
+	dm.routeDeployments(dc, errs,
+		func(d Deployer, c <-chan *Deployment) { d.RectifyDeletes(c, errs) },
+		func(d *Deployment, err error) RectificationError { return &DeleteError{Deployment: d, Err: err} })
+}
+
+// RectifyModifies implements Deployer. Pairs are routed by the kind of the
+// intended deployment's cluster.
+func (dm *DeployerMux) RectifyModifies(mc <-chan *DeploymentPair, errs chan<- RectificationError) {
+	chans := make(map[string]chan *DeploymentPair, len(dm.deployers))
+	wg := &sync.WaitGroup{}
+	for kind, d := range dm.deployers {
+		c := make(chan *DeploymentPair)
+		chans[kind] = c
+		wg.Add(1)
+		go func(d Deployer, c <-chan *DeploymentPair) { d.RectifyModifies(c, errs); wg.Done() }(d, c)
+	}
+
+	for pair := range mc {
+		if _, err := dm.deployerFor(pair.Post); err != nil {
+			errs <- &ChangeError{Deployments: pair, Err: err}
+			continue
+		}
+		chans[clusterKind(pair.Post.Cluster)] <- pair
+	}
+
+	for _, c := range chans {
+		close(c)
+	}
+	wg.Wait()
+}
+
+func (dm *DeployerMux) routeDeployments(
+	in <-chan *Deployment,
+	errs chan<- RectificationError,
+	rectify func(Deployer, <-chan *Deployment),
+	wrap func(*Deployment, error) RectificationError,
+) {
+	chans := make(map[string]chan *Deployment, len(dm.deployers))
+	wg := &sync.WaitGroup{}
+	for kind, d := range dm.deployers {
+		c := make(chan *Deployment)
+		chans[kind] = c
+		wg.Add(1)
+		go func(d Deployer, c <-chan *Deployment) { rectify(d, c); wg.Done() }(d, c)
+	}
+
+	for d := range in {
+		if _, err := dm.deployerFor(d); err != nil {
+			errs <- wrap(d, err)
+			continue
+		}
+		chans[clusterKind(d.Cluster)] <- d
+	}
+
+	for _, c := range chans {
+		close(c)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,131 @@
+package sous
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+type recordingDeployer struct {
+	sync.Mutex
+	running  Deployments
+	clusters []string
+	created  []DeployID
+	deleted  []DeployID
+	modified []DeployID
+}
+
+func newRecordingDeployer() *recordingDeployer {
+	return &recordingDeployer{running: NewDeployments()}
+}
+
+func (rd *recordingDeployer) RunningDeployments(from Clusters) (Deployments, error) {
+	for n := range from {
+		rd.clusters = append(rd.clusters, n)
+	}
+	return rd.running, nil
+}
+
+func (rd *recordingDeployer) RectifyCreates(dc <-chan *Deployment, errs chan<- RectificationError) {
+	for d := range dc {
+		rd.Lock()
+		rd.created = append(rd.created, d.ID())
+		rd.Unlock()
+	}
+}
+
+func (rd *recordingDeployer) RectifyDeletes(dc <-chan *Deployment, errs chan<- RectificationError) {
+	for d := range dc {
+		rd.Lock()
+		rd.deleted = append(rd.deleted, d.ID())
+		rd.Unlock()
+	}
+}
+
+func (rd *recordingDeployer) RectifyModifies(mc <-chan *DeploymentPair, errs chan<- RectificationError) {
+	for p := range mc {
+		rd.Lock()
+		rd.modified = append(rd.modified, p.ID())
+		rd.Unlock()
+	}
+}
+
+func muxFixtures() (*DeployerMux, *recordingDeployer, *recordingDeployer, Clusters) {
+	sing, kube := newRecordingDeployer(), newRecordingDeployer()
+	mux := NewDeployerMux()
+	mux.Register("singularity", sing)
+	mux.Register("kubernetes", kube)
+	clusters := Clusters{
+		"legacy": &Cluster{Name: "legacy"},
+		"sing":   &Cluster{Name: "sing", Kind: "singularity"},
+		"kube":   &Cluster{Name: "kube", Kind: "kubernetes"},
+		"nomad":  &Cluster{Name: "nomad", Kind: "nomad"},
+	}
+	return mux, sing, kube, clusters
+}
+
+func muxDeployment(clusters Clusters, name string) *Deployment {
+	return &Deployment{
+		SourceID:    MustParseSourceID(`github.com/ot/one,1.3.5`),
+		ClusterName: name,
+		Cluster:     clusters[name],
+	}
+}
+
+func TestDeployerMuxRunningDeployments(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mux, sing, kube, clusters := muxFixtures()
+	sing.running.Add(muxDeployment(clusters, "sing"))
+	kube.running.Add(muxDeployment(clusters, "kube"))
+
+	ds, err := mux.RunningDeployments(clusters)
+	require.NoError(err)
+	assert.Equal(2, ds.Len())
+	assert.Len(sing.clusters, 2)
+	assert.Contains(sing.clusters, "legacy")
+	assert.Equal([]string{"kube"}, kube.clusters)
+}
+
+func TestDeployerMuxRoutesByKind(t *testing.T) {
+	assert := assert.New(t)
+
+	mux, sing, kube, clusters := muxFixtures()
+
+	cc := make(chan *Deployment, 4)
+	for _, n := range []string{"legacy", "sing", "kube", "nomad"} {
+		cc <- muxDeployment(clusters, n)
+	}
+	close(cc)
+	mc := make(chan *DeploymentPair, 1)
+	mc <- &DeploymentPair{
+		name:  muxDeployment(clusters, "kube").ID(),
+		Prior: muxDeployment(clusters, "kube"),
+		Post:  muxDeployment(clusters, "kube"),
+	}
+	close(mc)
+
+	errs := make(chan RectificationError, 10)
+	mux.RectifyCreates(cc, errs)
+	mux.RectifyModifies(mc, errs)
+	close(errs)
+
+	assert.Len(sing.created, 2)
+	assert.Len(kube.created, 1)
+	assert.Len(kube.modified, 1)
+
+	var es []RectificationError
+	for e := range errs {
+		es = append(es, e)
+	}
+	if assert.Len(es, 1) {
+		ce, ok := es[0].(*CreateError)
+		if assert.True(ok) {
+			assert.IsType(&UnknownClusterKindError{}, ce.Err)
+			assert.Equal("nomad", ce.Deployment.ClusterName)
+		}
+	}
+}
@@ -38,6 +38,10 @@ type (
 		// Volumes enumerates the volume mappings required.
 		Volumes Volumes
 
+		// AutoRollback is copied from the manifest; see Manifest.AutoRollback.
+		// It doesn't participate in equality checks on the deployment.
+		AutoRollback bool
+
 		// Notes collected from the deployment's source.
 		Annotation
 	}
@@ -161,6 +165,13 @@ func (d *Deployment) Name() DeployID {
 	return d.ID()
 }
 
+// RollbackEnabled returns true if this deployment should be rolled back when
+// a new version of it fails to roll out, either because its manifest or its
+// cluster asks for that.
+func (d *Deployment) RollbackEnabled() bool {
+	return d.AutoRollback || (d.Cluster != nil && d.Cluster.AutoRollback)
+}
+
 // Equal returns true if two Deployments are equal.
 func (d *Deployment) Equal(o *Deployment) bool {
 	diff, _ := d.Diff(o)
@@ -187,16 +198,8 @@ func (d *Deployment) Diff(o *Deployment) (bool, []string) {
 	if d.Kind != o.Kind {
 		diff("kind; this: %q; other: %q", d.Kind, o.Kind)
 	}
-	if len(d.Owners) != len(o.Owners) {
-		// TODO: Make sure owners get written to Singularity, then uncomment next line.
-		//diff("number of owners; this: %+v; other: %+v", len(d.Owners), len(o.Owners))
-	}
-
-	for owner := range d.Owners {
-		if _, has := o.Owners[owner]; !has {
-			// TODO: Make sure owners get written to Singularity, then uncomment next line.
-			//diff("owner %s", owner)
-		}
+	if !d.Owners.Equal(o.Owners) {
+		diff("owners; this: %q; other: %q", d.Owners.Slice(), o.Owners.Slice())
 	}
 	_, configDiffs := d.DeployConfig.Diff(o.DeployConfig)
 	diffs = append(diffs, configDiffs...)
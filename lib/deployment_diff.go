@@ -1,6 +1,9 @@
 package sous
 
-import "strings"
+import (
+	"strings"
+	"sync"
+)
 
 type (
 	// DeploymentPair is a pair of deployments that represent a "before and after" style relationship
@@ -43,21 +46,29 @@ func (dp *DeploymentPair) ID() DeployID {
 	return dp.name
 }
 
+// collect drains all of d's channels, at once so that it doesn't matter which
+// order they are sent to in, and returns everything they carried.
 func (d *DiffChans) collect() diffSet {
 	ds := newDiffSet()
 
-	for g := range d.Deleted {
-		ds.Gone.Add(g)
-	}
-	for n := range d.Created {
-		ds.New.Add(n)
-	}
-	for m := range d.Modified {
-		ds.Changed = append(ds.Changed, m)
-	}
-	for s := range d.Retained {
-		ds.Same.Add(s)
-	}
+	wg := sync.WaitGroup{}
+	wg.Add(4)
+	drain := func(c chan *Deployment, into Deployments) {
+		for dep := range c {
+			into.Add(dep)
+		}
+		wg.Done()
+	}
+	go drain(d.Deleted, ds.Gone)
+	go drain(d.Created, ds.New)
+	go drain(d.Retained, ds.Same)
+	go func() {
+		for m := range d.Modified {
+			ds.Changed = append(ds.Changed, m)
+		}
+		wg.Done()
+	}()
+	wg.Wait()
 	return ds
 }
 
@@ -102,3 +102,19 @@ func TestRealDiff(t *testing.T) {
 		assert.Equal(string(it.SourceID.Location.Repo), repoFour)
 	}
 }
+
+func TestDiffOwners(t *testing.T) {
+	assert := assert.New(t)
+
+	running := makeDepl("https://github.com/opentable/one", 1)
+	intended := makeDepl("https://github.com/opentable/one", 1)
+	intended.Owners.Add("sam")
+
+	different, diffs := running.Diff(intended)
+	assert.True(different)
+	assert.Equal([]string{`owners; this: ["judson"]; other: ["judson" "sam"]`}, diffs)
+
+	intended.Owners.Remove("sam")
+	different, _ = running.Diff(intended)
+	assert.False(different)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"text/tabwriter"
+	"time"
 )
 
 func DumpDeployments(io io.Writer, ds Deployments) {
@@ -17,3 +18,48 @@ func DumpDeployments(io io.Writer, ds Deployments) {
 	}
 	w.Flush()
 }
+
+// DumpTombstones prints tombstones, along with when each deployment will be
+// deleted, as a table.
+func DumpTombstones(io io.Writer, ts Tombstones, clusters Clusters) {
+	w := &tabwriter.Writer{}
+	w.Init(io, 2, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Cluster\tManifest\tMissing since\tDelete after")
+
+	for _, t := range ts {
+		after := "never"
+		if exp, ok := t.Expiry(clusters); ok {
+			after = exp.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.Deployment.Cluster, t.Deployment.ManifestID, t.Since.Format(time.RFC3339), after)
+	}
+	w.Flush()
+}
+
+// DumpResolveRecords prints resolve records, with the changes each made, the
+// changes each was blocked from making, and the errors each encountered.
+func DumpResolveRecords(io io.Writer, recs []*ResolveRecord) {
+	w := &tabwriter.Writer{}
+	w.Init(io, 2, 4, 2, ' ', 0)
+
+	for _, rec := range recs {
+		fmt.Fprintf(w, "%s\t(%s)\t%s\n", rec.Started.Format(time.RFC3339), rec.Finished.Sub(rec.Started), rec.Filter.String())
+		for _, d := range rec.Diffs {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", d.Kind, d.Cluster, d.ManifestID, d.Version)
+			for _, c := range d.Changes {
+				fmt.Fprintf(w, "    %s\n", c)
+			}
+		}
+		for _, d := range rec.Blocked {
+			fmt.Fprintf(w, "  blocked %s\t%s\t%s\t%s\t%s\n", d.Kind, d.Cluster, d.ManifestID, d.Version, d.Reason)
+		}
+		if rec.FreezeOverride != "" {
+			fmt.Fprintf(w, "  freeze overridden by %s\n", rec.FreezeOverride)
+		}
+		for _, e := range rec.Errors {
+			fmt.Fprintf(w, "  error: %s\n", e)
+		}
+	}
+	w.Flush()
+}
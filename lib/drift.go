@@ -0,0 +1,98 @@
+package sous
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/opentable/sous/util/firsterr"
+	"github.com/pkg/errors"
+)
+
+// Drift describes how the running deployments differ from the intended ones.
+// Unlike a Plan, it reports every difference, including those a resolve
+// would hold back because of delete policies or frozen clusters.
+type Drift struct {
+	// Diffs are the deployments that would be created, deleted or modified,
+	// ordered by cluster and manifest ID.
+	Diffs []ResolveDiff
+}
+
+// Empty returns true if the running deployments are as intended.
+func (d *Drift) Empty() bool {
+	return len(d.Diffs) == 0
+}
+
+// Drift compares the running deployments in clusters with intended, after
+// filtering both with the Resolver's ResolveFilter. It changes nothing.
+func (r *Resolver) Drift(intended Deployments, clusters Clusters) (*Drift, error) {
+	var ads Deployments
+	err := firsterr.Returned(
+		func() (e error) { clusters = r.FilteredClusters(clusters); return },
+		func() (e error) { ads, e = r.Deployer.RunningDeployments(clusters); return },
+		func() (e error) { intended = intended.Filter(r.FilterDeployment); return },
+		func() (e error) { ads = ads.Filter(r.FilterDeployment); return },
+	)
+	if err != nil {
+		return nil, err
+	}
+	diffs := ads.Diff(intended)
+	ds := diffs.collect()
+
+	drift := &Drift{}
+	for _, d := range ds.New.Snapshot() {
+		drift.Diffs = append(drift.Diffs, newResolveDiff("create", d, nil))
+	}
+	for _, d := range ds.Gone.Snapshot() {
+		drift.Diffs = append(drift.Diffs, newResolveDiff("delete", d, nil))
+	}
+	for _, pair := range ds.Changed {
+		_, changes := pair.Prior.Diff(pair.Post)
+		drift.Diffs = append(drift.Diffs, newResolveDiff("modify", pair.Post, changes))
+	}
+
+	sort.Sort(resolveDiffsByID(drift.Diffs))
+	return drift, nil
+}
+
+type resolveDiffsByID []ResolveDiff
+
+func (ds resolveDiffsByID) Len() int      { return len(ds) }
+func (ds resolveDiffsByID) Swap(i, j int) { ds[i], ds[j] = ds[j], ds[i] }
+func (ds resolveDiffsByID) Less(i, j int) bool {
+	if ds[i].Cluster != ds[j].Cluster {
+		return ds[i].Cluster < ds[j].Cluster
+	}
+	if ds[i].ManifestID != ds[j].ManifestID {
+		return ds[i].ManifestID < ds[j].ManifestID
+	}
+	return ds[i].Kind < ds[j].Kind
+}
+
+// DumpDrift prints drift as a table, with the field changes under each
+// modified deployment.
+func DumpDrift(io io.Writer, d *Drift) {
+	w := &tabwriter.Writer{}
+	w.Init(io, 2, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Kind\tCluster\tManifest\tVersion")
+	for _, diff := range d.Diffs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", diff.Kind, diff.Cluster, diff.ManifestID, diff.Version)
+		for _, c := range diff.Changes {
+			fmt.Fprintf(w, "  %s\n", c)
+		}
+	}
+	w.Flush()
+}
+
+// WriteDrift serializes drift as JSON.
+func WriteDrift(w io.Writer, d *Drift) error {
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "serializing drift")
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,64 @@
+package sous
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+func TestDrift(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r, rd, intended, clusters := planFixtures()
+
+	drift, err := r.Drift(intended, clusters)
+	require.NoError(err)
+	require.Len(drift.Diffs, 3)
+
+	assert.Equal("modify", drift.Diffs[0].Kind)
+	assert.Equal("github.com/ot/one", drift.Diffs[0].ManifestID)
+	assert.Equal("2.0.0", drift.Diffs[0].Version)
+	assert.Len(drift.Diffs[0].Changes, 1)
+	assert.Equal("create", drift.Diffs[1].Kind)
+	assert.Equal("github.com/ot/three", drift.Diffs[1].ManifestID)
+	assert.Equal("delete", drift.Diffs[2].Kind)
+	assert.Equal("github.com/ot/two", drift.Diffs[2].ManifestID)
+
+	assert.Empty(rd.created)
+	assert.Empty(rd.deleted)
+	assert.Empty(rd.modified)
+
+	buf := &bytes.Buffer{}
+	require.NoError(WriteDrift(buf, drift))
+	read := &Drift{}
+	require.NoError(json.Unmarshal(buf.Bytes(), read))
+	assert.Equal(drift, read)
+}
+
+func TestDriftFiltered(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r, _, intended, clusters := planFixtures()
+	r.ResolveFilter = &ResolveFilter{Repo: "github.com/ot/two"}
+
+	drift, err := r.Drift(intended, clusters)
+	require.NoError(err)
+	if assert.Len(drift.Diffs, 1) {
+		assert.Equal("delete", drift.Diffs[0].Kind)
+	}
+}
+
+func TestDriftNone(t *testing.T) {
+	require := require.New(t)
+
+	r, rd, _, clusters := planFixtures()
+
+	drift, err := r.Drift(rd.running.Clone(), clusters)
+	require.NoError(err)
+	require.True(drift.Empty())
+}
@@ -1,11 +1,15 @@
 package sous
 
-import "log"
+import (
+	"log"
+	"sync"
+)
 
 type (
 	// DummyRectificationClient implements RectificationClient but doesn't act on the Mesos scheduler;
 	// instead it collects the changes that would be performed and options
 	DummyRectificationClient struct {
+		sync.Mutex
 		logger    *log.Logger
 		nameCache Registry
 		Created   []dummyRequest
@@ -21,14 +25,19 @@ type (
 		Res       Resources
 		E         Env
 		Vols      Volumes
+		Command   string
+		Args      []string
+		HC        HealthCheck
 	}
 
 	dummyRequest struct {
-		Cluster string
-		ID      string
-		Count   int
-		Kind    ManifestKind
-		Owners  OwnerSet
+		Cluster  string
+		ID       string
+		Count    int
+		Kind     ManifestKind
+		Owners   OwnerSet
+		Schedule string
+		TimeZone string
 	}
 
 	dummyDelete struct {
@@ -61,9 +70,11 @@ func (t *DummyRectificationClient) logf(f string, v ...interface{}) {
 
 // Deploy implements part of the RectificationClient interface
 func (t *DummyRectificationClient) Deploy(
-	cluster, depID, reqID, imageName string, res Resources, e Env, vols Volumes) error {
-	t.logf("Deploying instance %s %s %s %s %v %v %v", cluster, depID, reqID, imageName, res, e, vols)
-	t.Deployed = append(t.Deployed, dummyDeploy{cluster, depID, reqID, imageName, res, e, vols})
+	cluster, depID, reqID, imageName string, res Resources, e Env, vols Volumes, command string, args []string, hc HealthCheck) error {
+	t.logf("Deploying instance %s %s %s %s %v %v %v %q %q %v", cluster, depID, reqID, imageName, res, e, vols, command, args, hc)
+	t.Lock()
+	defer t.Unlock()
+	t.Deployed = append(t.Deployed, dummyDeploy{cluster, depID, reqID, imageName, res, e, vols, command, args, hc})
 	return nil
 }
 
@@ -72,9 +83,12 @@ func (t *DummyRectificationClient) PostRequest(
 	cluster, id string, count int,
 	kind ManifestKind,
 	owners OwnerSet,
+	schedule, timeZone string,
 ) error {
-	t.logf("Creating application %s %s %d %v %v", cluster, id, count, kind, owners)
-	t.Created = append(t.Created, dummyRequest{cluster, id, count, kind, owners})
+	t.logf("Creating application %s %s %d %v %v %q %q", cluster, id, count, kind, owners, schedule, timeZone)
+	t.Lock()
+	defer t.Unlock()
+	t.Created = append(t.Created, dummyRequest{cluster, id, count, kind, owners, schedule, timeZone})
 	return nil
 }
 
@@ -82,6 +96,8 @@ func (t *DummyRectificationClient) PostRequest(
 func (t *DummyRectificationClient) DeleteRequest(
 	cluster, reqid, message string) error {
 	t.logf("Deleting application %s %s %s", cluster, reqid, message)
+	t.Lock()
+	defer t.Unlock()
 	t.Deleted = append(t.Deleted, dummyDelete{cluster, reqid, message})
 	return nil
 }
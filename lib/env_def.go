@@ -0,0 +1,98 @@
+package sous
+
+import "sort"
+
+// The scopes an EnvDef may have.
+const (
+	// EnvScopeCluster variables are set by clusters, and reserved: manifests
+	// may not set them.
+	EnvScopeCluster = "cluster"
+	// EnvScopeManifest variables may be set by manifests. An empty scope is
+	// the same as EnvScopeManifest.
+	EnvScopeManifest = "manifest"
+)
+
+// Get returns the definition of the environment variable called name.
+func (evs EnvDefs) Get(name string) (EnvDef, bool) {
+	for _, ev := range evs {
+		if ev.Name == name {
+			return ev, true
+		}
+	}
+	return EnvDef{}, false
+}
+
+// Validate checks that each definition has a name, a known type and a known
+// scope, and that the values clusters give defined variables are of the
+// right type.
+func (evs EnvDefs) Validate(clusters Clusters) []Flaw {
+	var flaws []Flaw
+	for _, ev := range evs {
+		if ev.Name == "" {
+			flaws = append(flaws, unrepairableFlaw("env var definition has no name"))
+		}
+		if err := ev.Type.Validate(); err != nil {
+			flaws = append(flaws, unrepairableFlaw("env var %q: %s", ev.Name, err))
+		}
+		switch ev.Scope {
+		default:
+			flaws = append(flaws, unrepairableFlaw("env var %q has unknown scope %q", ev.Name, ev.Scope))
+		case "", EnvScopeCluster, EnvScopeManifest:
+		}
+	}
+	for _, clusterName := range clusters.names() {
+		env := clusters[clusterName].Env
+		for _, name := range env.names() {
+			ev, ok := evs.Get(name)
+			if !ok {
+				continue
+			}
+			if _, err := ev.Type.parse(string(env[name])); err != nil {
+				flaws = append(flaws, unrepairableFlaw("env var %q in cluster %q defaults: %s", name, clusterName, err))
+			}
+		}
+	}
+	return flaws
+}
+
+// check validates env, set by a manifest for clusterName, against these
+// definitions. Variables reserved for clusters, and values of the wrong type,
+// are unrepairable flaws. Variables which aren't defined are left alone.
+func (evs EnvDefs) check(env Env, clusterName string) []Flaw {
+	var flaws []Flaw
+	for _, name := range env.names() {
+		ev, ok := evs.Get(name)
+		if !ok {
+			continue
+		}
+		if ev.Scope == EnvScopeCluster {
+			flaws = append(flaws, unrepairableFlaw("env var %q is reserved for clusters, but is set for cluster %q", name, clusterName))
+			continue
+		}
+		if IsSecretRef(env[name]) {
+			continue
+		}
+		if _, err := ev.Type.parse(env[name]); err != nil {
+			flaws = append(flaws, unrepairableFlaw("env var %q for cluster %q: %s", name, clusterName, err))
+		}
+	}
+	return flaws
+}
+
+func (e Env) names() []string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (ed EnvDefaults) names() []string {
+	names := make([]string, 0, len(ed))
+	for name := range ed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
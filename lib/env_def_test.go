@@ -0,0 +1,46 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+)
+
+func TestEnvDefsCheck(t *testing.T) {
+	assert := assert.New(t)
+	evs := EnvDefs{
+		{Name: "CLUSTER_NAME", Scope: EnvScopeCluster},
+		{Name: "WORKERS", Scope: EnvScopeManifest, Type: VarTypeInt},
+		{Name: "GREETING"},
+	}
+
+	assert.Empty(evs.check(Env{"WORKERS": "4", "GREETING": "hi", "OTHER": "x"}, "test"))
+	assert.Len(evs.check(Env{"CLUSTER_NAME": "mine"}, "test"), 1)
+	assert.Len(evs.check(Env{"WORKERS": "four"}, "test"), 1)
+}
+
+func TestEnvDefsValidate(t *testing.T) {
+	assert := assert.New(t)
+	clusters := Clusters{
+		"test": &Cluster{Env: EnvDefaults{"WORKERS": "many", "CLUSTER_NAME": "test"}},
+	}
+	evs := EnvDefs{
+		{Name: "CLUSTER_NAME", Scope: EnvScopeCluster},
+		{Name: "WORKERS", Type: VarTypeInt},
+		{Name: "ODD", Scope: "global", Type: "complex"},
+	}
+
+	// WORKERS is not an int, and ODD has an unknown scope and type.
+	assert.Len(evs.Validate(clusters), 3)
+}
+
+func TestStateValidateEnvDefs(t *testing.T) {
+	s := resourceTestState(Resources{"cpus": "1", "memory": "256", "ports": "1", "disk": "20"})
+	s.Defs.EnvVars = EnvDefs{{Name: "CLUSTER_NAME", Scope: EnvScopeCluster}}
+	for _, m := range s.Manifests.Snapshot() {
+		spec := m.Deployments["test"]
+		spec.Env = Env{"CLUSTER_NAME": "mine"}
+		m.Deployments["test"] = spec
+	}
+	assert.Len(t, s.Validate(), 1)
+}
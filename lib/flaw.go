@@ -1,6 +1,10 @@
 package sous
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
 
 type (
 	// A Flaw captures the digression from a validation rule
@@ -46,6 +50,13 @@ func NewFlaw(desc string, repair func() error) GenericFlaw {
 	}
 }
 
+// unrepairableFlaw returns a flaw described by format and a, which cannot be
+// repaired.
+func unrepairableFlaw(format string, a ...interface{}) GenericFlaw {
+	desc := fmt.Sprintf(format, a...)
+	return NewFlaw(desc, func() error { return errors.Errorf("%s: cannot be repaired", desc) })
+}
+
 /*
 func FatalFlaw(fmt string, vals ...interface{}) GenericFlaw {
 	desc := fmt.Sprintf(fmt, vals...)
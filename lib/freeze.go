@@ -0,0 +1,128 @@
+package sous
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// A Freeze stops Sous from creating or changing deployments in a cluster,
+	// e.g. during an incident or a holiday. Deletions are still governed by
+	// the cluster's DeletePolicy.
+	Freeze struct {
+		// Frozen, if true, freezes the cluster until it is set false again.
+		Frozen bool
+		// Reason explains why the cluster is Frozen.
+		Reason string `yaml:",omitempty"`
+		// Windows are periods during which the cluster is frozen.
+		Windows []FreezeWindow `yaml:",omitempty"`
+	}
+
+	// A FreezeWindow is a period during which a cluster is frozen.
+	FreezeWindow struct {
+		// Start and End bound the window, in RFC3339 format, e.g.
+		// "2016-12-23T00:00:00Z".
+		Start, End string
+		// Reason explains why the cluster is frozen.
+		Reason string `yaml:",omitempty"`
+	}
+)
+
+// Bounds returns the parsed start and end of this window.
+func (w FreezeWindow) Bounds() (start, end time.Time, err error) {
+	if start, err = time.Parse(time.RFC3339, w.Start); err != nil {
+		return start, end, errors.Wrapf(err, "freeze window start")
+	}
+	end, err = time.Parse(time.RFC3339, w.End)
+	return start, end, errors.Wrapf(err, "freeze window end")
+}
+
+// FrozenAt returns true if this freeze is in effect at the given time, along
+// with the reason for it.
+func (f Freeze) FrozenAt(at time.Time) (bool, string) {
+	if f.Frozen {
+		return true, f.Reason
+	}
+	for _, w := range f.Windows {
+		start, end, err := w.Bounds()
+		if err != nil {
+			// A window we can't read is assumed to be in effect.
+			return true, fmt.Sprintf("%s (%v)", w.Reason, err)
+		}
+		if !at.Before(start) && at.Before(end) {
+			return true, w.Reason
+		}
+	}
+	return false, ""
+}
+
+// Validate checks that each of this freeze's windows can be read, and ends
+// after it starts.
+func (f Freeze) Validate() []Flaw {
+	var flaws []Flaw
+	for _, w := range f.Windows {
+		start, end, err := w.Bounds()
+		if err == nil && !end.After(start) {
+			err = errors.Errorf("freeze window from %s to %s ends before it starts", w.Start, w.End)
+		}
+		if err != nil {
+			flaws = append(flaws, unrepairableFlaw("%s", err))
+		}
+	}
+	return flaws
+}
+
+// frozen returns true if changes to d are blocked by a freeze on its cluster.
+// If the Resolver has a FreezeOverride, frozen logs the override and returns
+// false.
+func (r *Resolver) frozen(d *Deployment, at time.Time, rec *ResolveRecord) (bool, string) {
+	if d.Cluster == nil {
+		return false, ""
+	}
+	frozen, why := d.Cluster.Freeze.FrozenAt(at)
+	if !frozen {
+		return false, ""
+	}
+	if r.FreezeOverride != "" {
+		Log.Warn.Printf("%s overrode the freeze on cluster %s (%s) to change %s", r.FreezeOverride, d.ClusterName, why, d.ID().ManifestID)
+		rec.override(r.FreezeOverride)
+		return false, ""
+	}
+	return true, why
+}
+
+// guardFreezes passes on the diffs in dcs, except for creations and
+// modifications in frozen clusters, which are added to rec as blocked.
+func (r *Resolver) guardFreezes(dcs DiffChans, rec *ResolveRecord) DiffChans {
+	now := time.Now()
+	guarded := DiffChans{
+		Created:  make(chan *Deployment, cap(dcs.Created)),
+		Deleted:  dcs.Deleted,
+		Retained: dcs.Retained,
+		Modified: make(chan *DeploymentPair, cap(dcs.Modified)),
+	}
+	go func() {
+		for d := range dcs.Created {
+			if frozen, why := r.frozen(d, now, rec); frozen {
+				rec.block("create", d, nil, why)
+				continue
+			}
+			guarded.Created <- d
+		}
+		close(guarded.Created)
+	}()
+	go func() {
+		for pair := range dcs.Modified {
+			if frozen, why := r.frozen(pair.Post, now, rec); frozen {
+				_, changes := pair.Prior.Diff(pair.Post)
+				rec.block("modify", pair.Post, changes, why)
+				continue
+			}
+			guarded.Modified <- pair
+		}
+		close(guarded.Modified)
+	}()
+	return guarded
+}
@@ -0,0 +1,96 @@
+package sous
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+func TestFreezeFrozenAt(t *testing.T) {
+	assert := assert.New(t)
+
+	at := time.Date(2016, 12, 25, 12, 0, 0, 0, time.UTC)
+	holidays := FreezeWindow{Start: "2016-12-23T00:00:00Z", End: "2017-01-02T00:00:00Z", Reason: "holidays"}
+	past := FreezeWindow{Start: "2016-11-01T00:00:00Z", End: "2016-11-02T00:00:00Z"}
+
+	frozen, _ := Freeze{}.FrozenAt(at)
+	assert.False(frozen)
+
+	frozen, why := Freeze{Frozen: true, Reason: "incident"}.FrozenAt(at)
+	assert.True(frozen)
+	assert.Equal("incident", why)
+
+	frozen, why = Freeze{Windows: []FreezeWindow{past, holidays}}.FrozenAt(at)
+	assert.True(frozen)
+	assert.Equal("holidays", why)
+
+	frozen, _ = Freeze{Windows: []FreezeWindow{past}}.FrozenAt(at)
+	assert.False(frozen)
+}
+
+func TestFreezeValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Empty(Freeze{Frozen: true}.Validate())
+	assert.Empty(Freeze{Windows: []FreezeWindow{{Start: "2016-12-23T00:00:00Z", End: "2017-01-02T00:00:00Z"}}}.Validate())
+	assert.Len(Freeze{Windows: []FreezeWindow{{Start: "Dec 23", End: "2017-01-02T00:00:00Z"}}}.Validate(), 1)
+	assert.Len(Freeze{Windows: []FreezeWindow{{Start: "2017-01-02T00:00:00Z", End: "2016-12-23T00:00:00Z"}}}.Validate(), 1)
+}
+
+func TestResolveBlocksFrozenClusters(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r, rd, intended, clusters := planFixtures()
+	clusters["a"].Freeze = Freeze{Frozen: true, Reason: "incident"}
+	h := &memHistory{}
+	r.History = h
+
+	require.NoError(r.Resolve(intended, clusters))
+	assert.Empty(rd.created)
+	assert.Empty(rd.modified)
+	assert.Len(rd.deleted, 1)
+
+	require.Len(h.recs, 1)
+	assert.Len(h.recs[0].Blocked, 2)
+	for _, b := range h.recs[0].Blocked {
+		assert.Equal("incident", b.Reason)
+	}
+	assert.Empty(h.recs[0].FreezeOverride)
+}
+
+func TestResolveOverridesFreeze(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r, rd, intended, clusters := planFixtures()
+	clusters["a"].Freeze = Freeze{Frozen: true}
+	h := &memHistory{}
+	r.History = h
+	r.FreezeOverride = "jdoe"
+
+	require.NoError(r.Resolve(intended, clusters))
+	assert.Len(rd.created, 1)
+	assert.Len(rd.modified, 1)
+
+	require.Len(h.recs, 1)
+	assert.Empty(h.recs[0].Blocked)
+	assert.Equal("jdoe", h.recs[0].FreezeOverride)
+}
+
+func TestPlanShowsBlocked(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r, _, intended, clusters := planFixtures()
+	clusters["a"].Freeze = Freeze{Frozen: true}
+
+	p, err := r.Plan(intended, clusters)
+	require.NoError(err)
+	assert.Empty(p.Creates)
+	assert.Empty(p.Modifies)
+	assert.Len(p.Deletes, 1)
+	assert.Len(p.Blocked, 2)
+}
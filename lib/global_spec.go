@@ -0,0 +1,137 @@
+package sous
+
+import (
+	"sort"
+
+	"github.com/samsalisbury/semv"
+)
+
+// GlobalDeploySpec is the name of the DeploySpec in Manifest.Deployments
+// which isn't for a cluster. Its DeployConfig and Version are inherited by
+// the DeploySpec of each cluster, wherever that doesn't set its own.
+const GlobalDeploySpec = "Global"
+
+// Global returns the manifest's Global DeploySpec, if it has one.
+func (m *Manifest) Global() (DeploySpec, bool) {
+	spec, ok := m.Deployments[GlobalDeploySpec]
+	return spec, ok
+}
+
+// ClusterNames returns the names of the clusters the manifest is deployed
+// to, in order. The Global DeploySpec is not included.
+func (m *Manifest) ClusterNames() []string {
+	names := make([]string, 0, len(m.Deployments))
+	for name := range m.Deployments {
+		if name != GlobalDeploySpec {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ClusterSpec returns the DeploySpec for clusterName, with the Global
+// DeploySpec merged under it.
+func (m *Manifest) ClusterSpec(clusterName string) DeploySpec {
+	specs := []DeploySpec{m.Deployments[clusterName]}
+	if global, ok := m.Global(); ok {
+		specs = append(specs, global)
+	}
+	return flattenDeploySpecs(specs)
+}
+
+// ShapeLike returns m with its cluster DeploySpecs rewritten to inherit
+// from the Global DeploySpec of existing, so that a manifest rebuilt from
+// deployments keeps the shape of the manifest it was built from. m itself
+// is returned if existing is nil or has no Global DeploySpec, if m has its
+// own, or if some cluster's DeploySpec can't be expressed by inheriting from
+// existing's Global.
+func (m *Manifest) ShapeLike(existing *Manifest) *Manifest {
+	shaped, flaws := m.shapeLike(existing)
+	if len(flaws) > 0 {
+		return m
+	}
+	return shaped
+}
+
+// shapeLike is ShapeLike, but returns the flaws which stop m's cluster
+// DeploySpecs inheriting from existing's Global, rather than m.
+func (m *Manifest) shapeLike(existing *Manifest) (*Manifest, []Flaw) {
+	if existing == nil {
+		return m, nil
+	}
+	global, ok := existing.Global()
+	if !ok {
+		return m, nil
+	}
+	if _, ok := m.Global(); ok {
+		return m, nil
+	}
+	shaped := m.Clone()
+	shaped.Deployments = DeploySpecs{GlobalDeploySpec: global.Clone()}
+	var flaws []Flaw
+	for _, name := range m.ClusterNames() {
+		flaws = append(flaws, shaped.SetClusterSpec(name, m.Deployments[name])...)
+	}
+	return shaped, flaws
+}
+
+// SetClusterSpec sets the DeploySpec for clusterName to spec, leaving out
+// the values it shares with the Global DeploySpec, so that they are still
+// inherited from it.
+//
+// Values a cluster leaves out are inherited, so a cluster can't zero or
+// leave out a value the Global DeploySpec sets, for example by having no
+// instances, or no value for one of its env vars. SetClusterSpec returns a
+// flaw for each such value of spec, which the cluster will get from the
+// Global DeploySpec instead.
+func (m *Manifest) SetClusterSpec(clusterName string, spec DeploySpec) []Flaw {
+	want := spec
+	spec.DeployConfig = spec.DeployConfig.Clone()
+	if global, ok := m.Global(); ok {
+		var zeroVersion semv.Version
+		if spec.Version.Equals(global.Version) {
+			spec.Version = zeroVersion
+		}
+		if spec.NumInstances == global.NumInstances {
+			spec.NumInstances = 0
+		}
+		if spec.Command == global.Command {
+			spec.Command = ""
+		}
+		if ArgsEqual(spec.Args, global.Args) {
+			spec.Args = nil
+		}
+		if spec.Volumes.Equal(global.Volumes) {
+			spec.Volumes = nil
+		}
+		if spec.HealthCheck == global.HealthCheck {
+			spec.HealthCheck = HealthCheck{}
+		}
+		if spec.Schedule == global.Schedule && spec.ScheduleTimeZone == global.ScheduleTimeZone {
+			spec.Schedule, spec.ScheduleTimeZone = "", ""
+		}
+		for name, v := range global.Resources {
+			if gv, ok := spec.Resources[name]; ok && gv == v {
+				delete(spec.Resources, name)
+			}
+		}
+		for name, v := range global.Env {
+			if gv, ok := spec.Env[name]; ok && gv == v {
+				delete(spec.Env, name)
+			}
+		}
+	}
+	if m.Deployments == nil {
+		m.Deployments = DeploySpecs{}
+	}
+	m.Deployments[clusterName] = spec
+
+	var flaws []Flaw
+	_, diffs := m.ClusterSpec(clusterName).Diff(want)
+	for _, d := range diffs {
+		flaws = append(flaws, unrepairableFlaw("cluster %q would inherit a value it leaves out from the %s DeploySpec: %s",
+			clusterName, GlobalDeploySpec, d))
+	}
+	return flaws
+}
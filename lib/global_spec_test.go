@@ -0,0 +1,222 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/samsalisbury/semv"
+)
+
+func globalTestState() *State {
+	s := NewState()
+	s.Defs.Clusters = Clusters{
+		"cluster-1": &Cluster{Name: "cluster-1", BaseURL: "http://one"},
+		"cluster-2": &Cluster{Name: "cluster-2", BaseURL: "http://two"},
+	}
+	s.Manifests.Add(&Manifest{
+		Source: SourceLocation{Repo: "github.com/opentable/global"},
+		Owners: []string{"owner"},
+		Kind:   ManifestKindService,
+		Deployments: DeploySpecs{
+			GlobalDeploySpec: {
+				Version: semv.MustParse("1.0.0"),
+				DeployConfig: DeployConfig{
+					Resources: Resources{"cpus": "0.5", "ports": "1"},
+					Env:       Env{"SHARED": "yes"},
+				},
+			},
+			"cluster-1": {
+				DeployConfig: DeployConfig{
+					Resources:    Resources{"memory": "256"},
+					Env:          Env{"WHERE": "one"},
+					NumInstances: 2,
+				},
+			},
+			"cluster-2": {
+				DeployConfig: DeployConfig{
+					Resources:    Resources{"memory": "512"},
+					Env:          Env{"WHERE": "two"},
+					NumInstances: 4,
+				},
+			},
+		},
+	})
+	return s
+}
+
+func TestGlobalInheritance(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := globalTestState()
+	m := s.Manifests.Snapshot()[ManifestID{Source: SourceLocation{Repo: "github.com/opentable/global"}}]
+	require.Empty(s.Validate())
+
+	ds, err := s.DeploymentsFromManifest(m)
+	require.NoError(err)
+	require.Equal(2, ds.Len())
+	_, hasGlobal := m.Global()
+	assert.True(hasGlobal, "building deployments should not change the manifest")
+
+	one, ok := ds.Get(DeployID{ManifestID: m.ID(), Cluster: "cluster-1"})
+	require.True(ok)
+	assert.Equal("1.0.0", one.SourceID.Version.String())
+	assert.Equal(2, one.NumInstances)
+	assert.Equal(Resources{"cpus": "0.5", "memory": "256", "ports": "1"}, one.Resources)
+	assert.Equal(Env{"SHARED": "yes", "WHERE": "one"}, one.Env)
+
+	two, ok := ds.Get(DeployID{ManifestID: m.ID(), Cluster: "cluster-2"})
+	require.True(ok)
+	assert.Equal(4, two.NumInstances)
+	assert.Equal("512", two.Resources["memory"])
+}
+
+func TestGlobalRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := globalTestState()
+	ds, err := s.Deployments()
+	require.NoError(err)
+	original := s.Manifests.Clone()
+	require.NoError(s.UpdateManifests(ds))
+
+	for mid, expected := range original.Snapshot() {
+		actual, ok := s.Manifests.Get(mid)
+		require.True(ok)
+		different, diffs := actual.Diff(expected)
+		assert.False(different, "%v", diffs)
+	}
+
+	again, err := s.Deployments()
+	require.NoError(err)
+	for _, id := range ds.Keys() {
+		expected, _ := ds.Get(id)
+		actual, ok := again.Get(id)
+		require.True(ok)
+		different, diffs := actual.Diff(expected)
+		assert.False(different, "%v", diffs)
+	}
+}
+
+func TestGlobalValidate(t *testing.T) {
+	s := globalTestState()
+	for _, m := range s.Manifests.Snapshot() {
+		spec := m.Deployments["cluster-2"]
+		delete(spec.Resources, "memory")
+	}
+	flaws := s.Validate()
+	// Only memory is missing from cluster-2; Global supplies the rest.
+	if assert.Len(t, flaws, 1) {
+		assert.IsType(t, &MissingResourceFlaw{}, flaws[0])
+	}
+}
+
+func TestRoundTripWithoutGlobal(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := globalTestState()
+	plain := &Manifest{
+		Source: SourceLocation{Repo: "github.com/opentable/plain"},
+		Owners: []string{"owner"},
+		Kind:   ManifestKindService,
+		Deployments: DeploySpecs{
+			"cluster-1": {DeployConfig: DeployConfig{
+				Resources:    Resources{"cpus": "0.5", "memory": "256", "ports": "1"},
+				NumInstances: 2,
+			}},
+			"cluster-2": {DeployConfig: DeployConfig{
+				Resources:    Resources{"cpus": "0.5", "memory": "256", "ports": "1"},
+				NumInstances: 2,
+			}},
+		},
+	}
+	s.Manifests.Add(plain)
+
+	ds, err := s.Deployments()
+	require.NoError(err)
+	ms, err := ds.Manifests(s.Defs)
+	require.NoError(err)
+	actual, ok := ms.Get(plain.ID())
+	require.True(ok)
+	assert.Len(actual.Deployments, 2)
+	different, diffs := actual.Diff(plain)
+	assert.False(different, "%v", diffs)
+
+	require.NoError(s.UpdateManifests(ds))
+	actual, ok = s.Manifests.Get(plain.ID())
+	require.True(ok)
+	different, diffs = actual.Diff(plain)
+	assert.False(different, "%v", diffs)
+}
+
+func TestShapeLikeNeedsExpressibleSpecs(t *testing.T) {
+	existing := &Manifest{Deployments: DeploySpecs{
+		GlobalDeploySpec: {DeployConfig: DeployConfig{Env: Env{"SHARED": "yes"}}},
+		"cluster-1":      {},
+	}}
+	// Nothing can unset SHARED for cluster-1 once it inherits from Global.
+	rebuilt := &Manifest{Deployments: DeploySpecs{
+		"cluster-1": {DeployConfig: DeployConfig{Env: Env{}}},
+	}}
+	assert.True(t, rebuilt.ShapeLike(existing) == rebuilt)
+}
+
+func TestUpdateManifestsReportsUninheritableValues(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := globalTestState()
+	ds, err := s.Deployments()
+	require.NoError(err)
+	original := s.Manifests.Clone()
+
+	// cluster-1 drops an env var that Global sets, which it can only do by
+	// leaving it out, and so inheriting it after all.
+	for _, id := range ds.Keys() {
+		if id.Cluster == "cluster-1" {
+			d, _ := ds.Get(id)
+			delete(d.Env, "SHARED")
+		}
+	}
+	err = s.UpdateManifests(ds)
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "SHARED")
+	}
+	for mid, expected := range original.Snapshot() {
+		actual, _ := s.Manifests.Get(mid)
+		different, diffs := actual.Diff(expected)
+		assert.False(different, "%v", diffs)
+	}
+}
+
+func TestSetClusterSpecReportsZeroedValues(t *testing.T) {
+	m := &Manifest{Deployments: DeploySpecs{
+		GlobalDeploySpec: {DeployConfig: DeployConfig{NumInstances: 3}},
+	}}
+	flaws := m.SetClusterSpec("cluster-1", DeploySpec{DeployConfig: DeployConfig{NumInstances: 0}})
+	assert.Len(t, flaws, 1)
+	assert.Empty(t, m.SetClusterSpec("cluster-1", DeploySpec{DeployConfig: DeployConfig{NumInstances: 1}}))
+}
+
+func TestGlobalValidateHasNoSideEffects(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	m := &Manifest{
+		Kind: ManifestKindService,
+		Deployments: DeploySpecs{
+			GlobalDeploySpec: {DeployConfig: DeployConfig{Resources: Resources{"cpus": "0.5", "ports": "1"}}},
+			"cluster-1":      {},
+		},
+	}
+	flaws := m.Validate()
+	require.Len(flaws, 1)
+	assert.Nil(m.Deployments["cluster-1"].Resources, "validating changed the manifest")
+
+	require.NoError(flaws[0].Repair())
+	assert.Equal(Resources{"memory": "100"}, m.Deployments["cluster-1"].Resources)
+	assert.Empty(m.Validate())
+}
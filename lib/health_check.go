@@ -0,0 +1,66 @@
+package sous
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A HealthCheck describes how the scheduler should decide whether instances
+// of a deployment are healthy. The zero HealthCheck means no health check.
+type HealthCheck struct {
+	// URIPath is the path requested from each instance, e.g. "/health". A
+	// response with a 2xx status means the instance is healthy.
+	URIPath string `yaml:",omitempty"`
+	// PortIndex picks which of the instance's ports is checked, counting
+	// from 0.
+	PortIndex int `yaml:",omitempty"`
+	// StartupDelaySeconds is how long a new instance has to start passing
+	// health checks before it's considered failed.
+	StartupDelaySeconds int `yaml:",omitempty"`
+	// TimeoutSeconds is how long a single check waits for a response.
+	TimeoutSeconds int `yaml:",omitempty"`
+	// IntervalSeconds is how long to wait between checks.
+	IntervalSeconds int `yaml:",omitempty"`
+}
+
+// Enabled returns true if this describes a health check at all.
+func (hc HealthCheck) Enabled() bool {
+	return hc != HealthCheck{}
+}
+
+// Validate checks that the health check is complete and consistent with the
+// number of ports in rezs.
+func (hc HealthCheck) Validate(rezs Resources) []Flaw {
+	if !hc.Enabled() {
+		return nil
+	}
+	var flaws []Flaw
+	if hc.URIPath == "" {
+		flaws = append(flaws, unrepairableFlaw("health check has no URI path"))
+	} else if !strings.HasPrefix(hc.URIPath, "/") {
+		flaws = append(flaws, unrepairableFlaw("health check URI path %q doesn't start with /", hc.URIPath))
+	}
+	if hc.PortIndex < 0 {
+		flaws = append(flaws, unrepairableFlaw("health check port index %d is negative", hc.PortIndex))
+	} else if _, has := rezs["ports"]; has && int32(hc.PortIndex) >= rezs.Ports() {
+		flaws = append(flaws, unrepairableFlaw("health check port index %d, but only %d ports", hc.PortIndex, rezs.Ports()))
+	}
+	if hc.StartupDelaySeconds < 0 {
+		flaws = append(flaws, unrepairableFlaw("health check start-up delay %d is negative", hc.StartupDelaySeconds))
+	}
+	if hc.TimeoutSeconds < 0 {
+		flaws = append(flaws, unrepairableFlaw("health check timeout %d is negative", hc.TimeoutSeconds))
+	}
+	if hc.IntervalSeconds < 0 {
+		flaws = append(flaws, unrepairableFlaw("health check interval %d is negative", hc.IntervalSeconds))
+	}
+	return flaws
+}
+
+func (hc HealthCheck) String() string {
+	if !hc.Enabled() {
+		return "none"
+	}
+	return fmt.Sprintf("%s on port #%d, start-up %ds, timeout %ds, every %ds",
+		hc.URIPath, hc.PortIndex, hc.StartupDelaySeconds, hc.TimeoutSeconds, hc.IntervalSeconds)
+}
@@ -0,0 +1,20 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+)
+
+func TestHealthCheckValidate(t *testing.T) {
+	assert := assert.New(t)
+	rezs := Resources{"cpus": "0.1", "memory": "32", "ports": "2"}
+
+	assert.Empty(HealthCheck{}.Validate(rezs))
+	assert.Empty(HealthCheck{URIPath: "/health", PortIndex: 1, TimeoutSeconds: 5}.Validate(rezs))
+
+	assert.Len(HealthCheck{PortIndex: 1}.Validate(rezs), 1)
+	assert.Len(HealthCheck{URIPath: "health"}.Validate(rezs), 1)
+	assert.Len(HealthCheck{URIPath: "/health", PortIndex: 2}.Validate(rezs), 1)
+	assert.Len(HealthCheck{URIPath: "/health", StartupDelaySeconds: -1, IntervalSeconds: -1}.Validate(rezs), 2)
+}
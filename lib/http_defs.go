@@ -0,0 +1,110 @@
+package sous
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// ReadDefs gets the definitions from the server, and the Etag that
+// WriteDefs needs to replace them.
+func (hsm *HTTPStateManager) ReadDefs() (Defs, string, error) {
+	ds := Defs{}
+	etag, err := hsm.getCachedEtag("./defs", &ds)
+	return ds, etag, errors.Wrapf(err, "getting defs")
+}
+
+// WriteDefs replaces the definitions on the server, if they are still those
+// that etag was read with. The server refuses definitions that its manifests
+// don't satisfy.
+func (hsm *HTTPStateManager) WriteDefs(defs Defs, etag string) error {
+	return errors.Wrap(hsm.putJSON("./defs", nil, defs, etag), "writing defs")
+}
+
+// ReadCluster gets the definition of the named cluster from the server, and
+// the Etag that WriteCluster and DeleteCluster need to change it.
+func (hsm *HTTPStateManager) ReadCluster(name string) (*Cluster, string, error) {
+	c := &Cluster{}
+	etag, err := hsm.getCachedEtag("./defs/cluster?"+clusterQuery(name).Encode(), c)
+	return c, etag, errors.Wrapf(err, "getting cluster %q", name)
+}
+
+// WriteCluster adds the named cluster to the definitions on the server, or
+// replaces it if it is still the one that etag was read with. With no etag,
+// the cluster is only added if there isn't one of that name already.
+func (hsm *HTTPStateManager) WriteCluster(name string, c *Cluster, etag string) error {
+	return errors.Wrapf(hsm.putJSON("./defs/cluster", clusterQuery(name), c, etag), "writing cluster %q", name)
+}
+
+// DeleteCluster removes the named cluster from the definitions on the
+// server, if it is still the one that etag was read with. The server refuses
+// if any manifest deploys to the cluster.
+func (hsm *HTTPStateManager) DeleteCluster(name, etag string) error {
+	u, err := hsm.resourceURL("./defs/cluster", clusterQuery(name))
+	if err != nil {
+		return err
+	}
+	rq, err := http.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return err
+	}
+	rq.Header.Add("If-Match", etag)
+	return errors.Wrapf(hsm.do(rq), "deleting cluster %q", name)
+}
+
+func clusterQuery(name string) url.Values {
+	return url.Values{"name": []string{name}}
+}
+
+func (hsm *HTTPStateManager) resourceURL(path string, query url.Values) (string, error) {
+	u, err := hsm.serverURL.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// putJSON PUTs v to path, if what is there now is what etag was read with.
+// With no etag, v is only PUT if there is nothing at path yet. The server
+// refuses with 412 Precondition Failed otherwise, and that is returned rather
+// than overwriting someone else's change.
+func (hsm *HTTPStateManager) putJSON(path string, query url.Values, v interface{}, etag string) error {
+	u, err := hsm.resourceURL(path, query)
+	if err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		return err
+	}
+	rq, err := http.NewRequest("PUT", u, buf)
+	if err != nil {
+		return err
+	}
+	if etag != "" {
+		rq.Header.Add("If-Match", etag)
+	} else {
+		rq.Header.Add("If-None-Match", "*")
+	}
+	return hsm.do(rq)
+}
+
+// do makes rq, and returns an error including the response body, which
+// explains what was wrong, if it isn't successful.
+func (hsm *HTTPStateManager) do(rq *http.Request) error {
+	rz, err := hsm.Client.Do(rq)
+	if err != nil {
+		return err
+	}
+	defer rz.Body.Close()
+	if rz.StatusCode >= 200 && rz.StatusCode < 300 {
+		return nil
+	}
+	body, _ := ioutil.ReadAll(rz.Body)
+	return errors.Errorf("%s %s: %s %s", rq.Method, rq.URL, rz.Status, bytes.TrimSpace(body))
+}
@@ -0,0 +1,34 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+func TestWriteDefsRefusesStaleEtag(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	srv := newFakeStateServer()
+	defer srv.Close()
+	hsm, err := NewHTTPStateManager(srv.URL)
+	require.NoError(err)
+
+	defs, etag, err := hsm.ReadDefs()
+	require.NoError(err)
+	assert.NotEqual("", etag)
+
+	defs.DockerRepo = "first"
+	require.NoError(hsm.WriteDefs(defs, etag))
+
+	// A second write with the Etag from before the first is refused, rather
+	// than overwriting it.
+	defs.DockerRepo = "second"
+	assert.Error(hsm.WriteDefs(defs, etag))
+
+	defs, _, err = hsm.ReadDefs()
+	require.NoError(err)
+	assert.Equal("first", defs.DockerRepo)
+}
@@ -0,0 +1,40 @@
+package sous
+
+import "github.com/pkg/errors"
+
+type rollbacksWrapper struct {
+	Rollbacks Rollbacks
+}
+
+// getRollbacks gets the rollbacks from the server, and the Etag that
+// putRollbacks needs to replace them. A server too old to keep rollbacks has
+// none.
+func (hsm *HTTPStateManager) getRollbacks() (Rollbacks, string, error) {
+	w := &rollbacksWrapper{}
+	etag, err := hsm.getCachedEtag("./rollbacks", w)
+	if isNotFound(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", errors.Wrap(err, "getting rollbacks")
+	}
+	return w.Rollbacks, etag, nil
+}
+
+// putRollbacks replaces the rollbacks on the server, if they differ from
+// those it had when the state was read, and nobody else changed them since.
+func (hsm *HTTPStateManager) putRollbacks(rs Rollbacks) error {
+	if !listChanged(hsm.cached.Rollbacks, rs) {
+		return nil
+	}
+	if err := hsm.putJSON("./rollbacks", nil, rollbacksWrapper{Rollbacks: rs}, hsm.rollbacksEtag); err != nil {
+		return errors.Wrap(err, "writing rollbacks")
+	}
+	// The server's new Etag is only known by reading them again.
+	rs, etag, err := hsm.getRollbacks()
+	if err != nil {
+		return err
+	}
+	hsm.cached.Rollbacks, hsm.rollbacksEtag = rs, etag
+	return nil
+}
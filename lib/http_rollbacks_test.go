@@ -0,0 +1,72 @@
+package sous
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/samsalisbury/semv"
+)
+
+func TestHTTPStateManagerKeepsRollbacks(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	srv := newFakeStateServer()
+	defer srv.Close()
+	hsm, err := NewHTTPStateManager(srv.URL)
+	require.NoError(err)
+
+	keeper := StateRollbackKeeper{StateManager: hsm}
+	rs, err := keeper.ReadRollbacks()
+	require.NoError(err)
+	assert.Len(rs, 0)
+
+	id := DeployID{ManifestID: ManifestID{Source: SourceLocation{Repo: "gh"}}, Cluster: "a"}
+	at := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(keeper.WriteRollbacks(Rollbacks{{
+		Deployment: id,
+		Version:    semv.MustParse("2.0.0"),
+		At:         at,
+		Reason:     "crashed",
+	}}))
+
+	hsm, err = NewHTTPStateManager(srv.URL)
+	require.NoError(err)
+	rs, err = StateRollbackKeeper{StateManager: hsm}.ReadRollbacks()
+	require.NoError(err)
+	require.Len(rs, 1)
+	assert.Equal(id, rs[0].Deployment)
+	assert.Equal("2.0.0", rs[0].Version.String())
+	assert.True(at.Equal(rs[0].At))
+	assert.Equal("crashed", rs[0].Reason)
+
+	require.NoError(StateRollbackKeeper{StateManager: hsm}.WriteRollbacks(nil))
+	state, err := hsm.ReadState()
+	require.NoError(err)
+	assert.Len(state.Rollbacks, 0)
+}
+
+func TestHTTPStateManagerRefusesStaleRollbacks(t *testing.T) {
+	require := require.New(t)
+
+	srv := newFakeStateServer()
+	defer srv.Close()
+	first, err := NewHTTPStateManager(srv.URL)
+	require.NoError(err)
+	second, err := NewHTTPStateManager(srv.URL)
+	require.NoError(err)
+
+	firstState, err := first.ReadState()
+	require.NoError(err)
+	secondState, err := second.ReadState()
+	require.NoError(err)
+
+	id := DeployID{ManifestID: ManifestID{Source: SourceLocation{Repo: "gh"}}, Cluster: "a"}
+	firstState.Rollbacks = Rollbacks{{Deployment: id, Version: semv.MustParse("2.0.0"), At: time.Now()}}
+	require.NoError(first.WriteState(firstState))
+
+	secondState.Rollbacks = Rollbacks{{Deployment: id, Version: semv.MustParse("3.0.0"), At: time.Now()}}
+	require.Error(second.WriteState(secondState))
+}
@@ -0,0 +1,144 @@
+package sous
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// A cachedResponse is a response from a Sous server, as kept in an
+// HTTPStateManager's CacheDir.
+type cachedResponse struct {
+	Etag string
+	Body json.RawMessage
+}
+
+// A notFoundError is returned by getCached if the server has nothing at the
+// path, for example because it is older than the resource.
+type notFoundError struct {
+	url string
+}
+
+func (e notFoundError) Error() string {
+	return fmt.Sprintf("GET %s: not found", e.url)
+}
+
+// isNotFound returns true if err is a notFoundError, however wrapped.
+func isNotFound(err error) bool {
+	_, is := errors.Cause(err).(notFoundError)
+	return is
+}
+
+// getCached GETs path from the server, and decodes the JSON response into v.
+// If hsm has a CacheDir, the response is kept there, and revalidated with
+// If-None-Match next time, so that it's only downloaded again if it changed.
+func (hsm *HTTPStateManager) getCached(path string, v interface{}) error {
+	_, err := hsm.getCachedEtag(path, v)
+	return err
+}
+
+// getCachedEtag is getCached, and also returns the Etag of the response, to
+// guard a later change to path.
+func (hsm *HTTPStateManager) getCachedEtag(path string, v interface{}) (string, error) {
+	url, err := hsm.serverURL.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	rq, err := http.NewRequest("GET", url.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	cachePath := hsm.cachePath(url.String())
+	cached := hsm.readCache(cachePath)
+	if cached != nil {
+		rq.Header.Set("If-None-Match", cached.Etag)
+	}
+
+	rz, err := hsm.Client.Do(rq)
+	if err != nil {
+		return "", err
+	}
+	defer rz.Body.Close()
+
+	var body []byte
+	var etag string
+	switch {
+	default:
+		return "", errors.Errorf("GET %s: %s", url, rz.Status)
+	case rz.StatusCode == http.StatusNotFound:
+		return "", notFoundError{url: url.String()}
+	case rz.StatusCode == http.StatusNotModified && cached != nil:
+		body, etag = cached.Body, cached.Etag
+	case rz.StatusCode == http.StatusOK:
+		body, err = ioutil.ReadAll(rz.Body)
+		if err != nil {
+			return "", err
+		}
+		etag = rz.Header.Get("Etag")
+		if etag != "" && cachePath != "" {
+			// A cache we can't write only costs a download next time.
+			hsm.writeCache(cachePath, &cachedResponse{Etag: etag, Body: body})
+		}
+	}
+	return etag, json.Unmarshal(body, v)
+}
+
+// cachePath returns the path of the file url's response is cached in, or ""
+// if hsm doesn't cache responses.
+func (hsm *HTTPStateManager) cachePath(url string) string {
+	if hsm.CacheDir == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(hsm.CacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// readCache returns the response cached at path, or nil if there isn't one
+// that can be read.
+func (hsm *HTTPStateManager) readCache(path string) *cachedResponse {
+	if path == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	cached := &cachedResponse{}
+	if err := json.Unmarshal(b, cached); err != nil || cached.Etag == "" {
+		return nil
+	}
+	return cached
+}
+
+func (hsm *HTTPStateManager) writeCache(path string, cached *cachedResponse) error {
+	b, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// Write then rename, so that concurrent commands never read half a file.
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,66 @@
+package sous
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestGetCachedRevalidates(t *testing.T) {
+	etag := "w/defs"
+	downloads := 0
+	h := func(rw http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		rw.Header().Add("Etag", etag)
+		rw.Write([]byte(`{"DockerRepo": "reponame"}`))
+	}
+	srv := httptest.NewServer(http.HandlerFunc(h))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "sous-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i := 0; i < 2; i++ {
+		// A new HTTPStateManager each time, as for separate sous commands.
+		hsm, err := NewHTTPStateManager(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hsm.CacheDir = dir
+		defs, err := hsm.getDefs()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if defs.DockerRepo != "reponame" {
+			t.Errorf("Got DockerRepo %q, want %q", defs.DockerRepo, "reponame")
+		}
+	}
+	if downloads != 1 {
+		t.Errorf("Downloaded defs %d times, want 1", downloads)
+	}
+}
+
+func TestGetCachedReportsErrors(t *testing.T) {
+	h := func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(h))
+	defer srv.Close()
+
+	hsm, err := NewHTTPStateManager(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hsm.getDefs(); err == nil {
+		t.Errorf("Expected an error getting defs from a failing server")
+	}
+}
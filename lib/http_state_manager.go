@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"reflect"
 
 	"github.com/pkg/errors"
 )
@@ -16,6 +17,12 @@ type (
 	HTTPStateManager struct {
 		serverURL *url.URL
 		cached    *State
+		// tombstonesEtag and rollbacksEtag are the Etags of the tombstones
+		// and rollbacks in cached, which guard writing them back.
+		tombstonesEtag, rollbacksEtag string
+		// CacheDir, if set, is where responses from the server are cached
+		// between runs.
+		CacheDir string
 		http.Client
 	}
 
@@ -32,11 +39,6 @@ func (g *gdmWrapper) manifests(defs Defs) (Manifests, error) {
 	return ds.Manifests(defs)
 }
 
-func (g *gdmWrapper) fromJSON(reader io.Reader) {
-	dec := json.NewDecoder(reader)
-	dec.Decode(g)
-}
-
 // NewHTTPStateManager creates a new HTTPStateManager
 func NewHTTPStateManager(us string) (*HTTPStateManager, error) {
 	u, err := url.Parse(us)
@@ -47,32 +49,14 @@ func NewHTTPStateManager(us string) (*HTTPStateManager, error) {
 
 func (hsm *HTTPStateManager) getDefs() (Defs, error) {
 	ds := Defs{}
-	url, err := hsm.serverURL.Parse("./defs")
-	if err != nil {
-		return ds, errors.Wrapf(err, "getting defs")
-	}
-	rq, err := hsm.Client.Get(url.String())
-	if err != nil {
-		return ds, errors.Wrapf(err, "getting defs")
-	}
-
-	dec := json.NewDecoder(rq.Body)
-
-	return ds, errors.Wrapf(dec.Decode(&ds), "getting defs")
+	return ds, errors.Wrapf(hsm.getCached("./defs", &ds), "getting defs")
 }
 
 func (hsm *HTTPStateManager) getManifests(defs Defs) (Manifests, error) {
-	url, err := hsm.serverURL.Parse("./gdm")
-	if err != nil {
-		return Manifests{}, errors.Wrapf(err, "getting manifests")
-	}
-	gdmRq, err := hsm.Client.Get(url.String())
-	if err != nil {
+	gdm := &gdmWrapper{}
+	if err := hsm.getCached("./gdm", gdm); err != nil {
 		return Manifests{}, errors.Wrapf(err, "getting manifests")
 	}
-	gdm := &gdmWrapper{}
-	gdm.fromJSON(gdmRq.Body)
-	gdmRq.Body.Close()
 	return gdm.manifests(defs)
 }
 
@@ -86,11 +70,22 @@ func (hsm *HTTPStateManager) ReadState() (*State, error) {
 	if err != nil {
 		return nil, err
 	}
+	ts, tsEtag, err := hsm.getTombstones()
+	if err != nil {
+		return nil, err
+	}
+	rs, rsEtag, err := hsm.getRollbacks()
+	if err != nil {
+		return nil, err
+	}
 
 	hsm.cached = &State{
-		Defs:      defs,
-		Manifests: ms,
+		Defs:       defs,
+		Manifests:  ms,
+		Tombstones: ts,
+		Rollbacks:  rs,
 	}
+	hsm.tombstonesEtag, hsm.rollbacksEtag = tsEtag, rsEtag
 	return hsm.cached.Clone(), nil
 }
 
@@ -116,7 +111,22 @@ func (hsm *HTTPStateManager) WriteState(ws *State) error {
 	}
 	diff := cds.Diff(wds)
 	cchs := diff.Concentrate(ws.Defs)
-	return hsm.process(cchs)
+	if err := hsm.process(cchs); err != nil {
+		return err
+	}
+	if err := hsm.putTombstones(ws.Tombstones); err != nil {
+		return err
+	}
+	return hsm.putRollbacks(ws.Rollbacks)
+}
+
+// listChanged returns true if the slices a and b have different contents.
+// Empty and nil slices are the same.
+func listChanged(a, b interface{}) bool {
+	if reflect.ValueOf(a).Len() == 0 && reflect.ValueOf(b).Len() == 0 {
+		return false
+	}
+	return !reflect.DeepEqual(a, b)
 }
 
 func (hsm *HTTPStateManager) process(dc DiffConcentrator) error {
@@ -303,7 +313,7 @@ func (hsm *HTTPStateManager) del(m *Manifest) error {
 		return errors.Errorf("GET %s to delete, %s: %#v", murl, grz.Status, m)
 	}
 	rm := hsm.jsonManifest(grz.Body)
-	different, differences := rm.Diff(m)
+	different, differences := rm.Diff(m.ShapeLike(rm))
 	if different {
 		return errors.Errorf("Remote and deleted manifests don't match: %#v", differences)
 	}
@@ -344,7 +354,7 @@ func (hsm *HTTPStateManager) modify(mp *ManifestPair) error {
 		return errors.Errorf("%s: %#v", grz.Status, bf)
 	}
 	rm := hsm.jsonManifest(grz.Body)
-	different, differences := rm.Diff(bf)
+	different, differences := rm.Diff(bf.ShapeLike(rm))
 	if different {
 		return errors.Errorf("Remote and prior manifests don't match: %#v", differences)
 	}
@@ -355,7 +365,9 @@ func (hsm *HTTPStateManager) modify(mp *ManifestPair) error {
 		return err
 	}
 
-	prq, err := http.NewRequest("PUT", murl, hsm.manifestJSON(af))
+	// The server's manifest may inherit from a Global DeploySpec, which the
+	// manifests rebuilt from deployments don't have.
+	prq, err := http.NewRequest("PUT", murl, hsm.manifestJSON(af.ShapeLike(rm)))
 	if err != nil {
 		return errors.Wrapf(err, "modify request")
 	}
@@ -369,3 +381,30 @@ func (hsm *HTTPStateManager) modify(mp *ManifestPair) error {
 	}
 	return nil
 }
+
+// ManifestHistory implements ManifestHistorian for HTTPStateManager, by
+// asking the server.
+func (hsm *HTTPStateManager) ManifestHistory(mid ManifestID) ([]ManifestChange, error) {
+	murl, err := hsm.manifestURL(&Manifest{Source: mid.Source, Flavor: mid.Flavor})
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(murl)
+	if err != nil {
+		return nil, err
+	}
+	u.Path += "/history"
+	rz, err := hsm.Client.Get(u.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting history of %s", mid)
+	}
+	defer rz.Body.Close()
+	if rz.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if rz.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("getting history of %s: %s", mid, rz.Status)
+	}
+	history := struct{ Changes []ManifestChange }{}
+	return history.Changes, errors.Wrapf(json.NewDecoder(rz.Body).Decode(&history), "getting history of %s", mid)
+}
@@ -0,0 +1,40 @@
+package sous
+
+import "github.com/pkg/errors"
+
+type tombstonesWrapper struct {
+	Tombstones Tombstones
+}
+
+// getTombstones gets the tombstones from the server, and the Etag that
+// putTombstones needs to replace them. A server too old to keep tombstones
+// has none.
+func (hsm *HTTPStateManager) getTombstones() (Tombstones, string, error) {
+	w := &tombstonesWrapper{}
+	etag, err := hsm.getCachedEtag("./tombstones", w)
+	if isNotFound(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", errors.Wrap(err, "getting tombstones")
+	}
+	return w.Tombstones, etag, nil
+}
+
+// putTombstones replaces the tombstones on the server, if they differ from
+// those it had when the state was read, and nobody else changed them since.
+func (hsm *HTTPStateManager) putTombstones(ts Tombstones) error {
+	if !listChanged(hsm.cached.Tombstones, ts) {
+		return nil
+	}
+	if err := hsm.putJSON("./tombstones", nil, tombstonesWrapper{Tombstones: ts}, hsm.tombstonesEtag); err != nil {
+		return errors.Wrap(err, "writing tombstones")
+	}
+	// The server's new Etag is only known by reading them again.
+	ts, etag, err := hsm.getTombstones()
+	if err != nil {
+		return err
+	}
+	hsm.cached.Tombstones, hsm.tombstonesEtag = ts, etag
+	return nil
+}
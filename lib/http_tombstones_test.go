@@ -0,0 +1,131 @@
+package sous
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+// fakeStateServer keeps the JSON PUT to each path, and serves it back with an
+// Etag, as a Sous server does. Every PUT changes every Etag.
+type fakeStateServer struct {
+	sync.Mutex
+	bodies  map[string][]byte
+	version int
+}
+
+func newFakeStateServer() *httptest.Server {
+	return httptest.NewServer(&fakeStateServer{bodies: map[string][]byte{
+		"/defs":       []byte(`{}`),
+		"/gdm":        []byte(`{"Deployments": []}`),
+		"/tombstones": []byte(`{"Tombstones": []}`),
+		"/rollbacks":  []byte(`{"Rollbacks": []}`),
+	}})
+}
+
+func (f *fakeStateServer) etag(path string) string {
+	return fmt.Sprintf("w/%s/%d", path, f.version)
+}
+
+func (f *fakeStateServer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	body, exists := f.bodies[r.URL.Path]
+	switch r.Method {
+	default:
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+	case "GET":
+		if !exists {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		rw.Header().Set("Etag", f.etag(r.URL.Path))
+		rw.Write(body)
+	case "PUT":
+		if !exists || r.Header.Get("If-Match") != f.etag(r.URL.Path) {
+			rw.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		f.bodies[r.URL.Path], _ = ioutil.ReadAll(r.Body)
+		f.version++
+	}
+}
+
+func TestHTTPStateManagerKeepsTombstones(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	srv := newFakeStateServer()
+	defer srv.Close()
+	hsm, err := NewHTTPStateManager(srv.URL)
+	require.NoError(err)
+
+	keeper := StateTombstoneKeeper{StateManager: hsm}
+	ts, err := keeper.ReadTombstones()
+	require.NoError(err)
+	assert.Len(ts, 0)
+
+	id := DeployID{ManifestID: ManifestID{Source: SourceLocation{Repo: "gh"}}, Cluster: "grace"}
+	since := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(keeper.WriteTombstones(Tombstones{{Deployment: id, Since: since}}))
+
+	hsm, err = NewHTTPStateManager(srv.URL)
+	require.NoError(err)
+	state, err := hsm.ReadState()
+	require.NoError(err)
+	require.Len(state.Tombstones, 1)
+	assert.Equal(id, state.Tombstones[0].Deployment)
+	assert.True(since.Equal(state.Tombstones[0].Since))
+}
+
+func TestHTTPStateManagerRefusesStaleTombstones(t *testing.T) {
+	require := require.New(t)
+
+	srv := newFakeStateServer()
+	defer srv.Close()
+	first, err := NewHTTPStateManager(srv.URL)
+	require.NoError(err)
+	second, err := NewHTTPStateManager(srv.URL)
+	require.NoError(err)
+
+	firstState, err := first.ReadState()
+	require.NoError(err)
+	secondState, err := second.ReadState()
+	require.NoError(err)
+
+	id := DeployID{ManifestID: ManifestID{Source: SourceLocation{Repo: "gh"}}, Cluster: "grace"}
+	firstState.Tombstones = Tombstones{{Deployment: id, Since: time.Now()}}
+	require.NoError(first.WriteState(firstState))
+
+	// second read the tombstones before first changed them, so it mustn't
+	// overwrite them.
+	id.Cluster = "other"
+	secondState.Tombstones = Tombstones{{Deployment: id, Since: time.Now()}}
+	require.Error(second.WriteState(secondState))
+}
+
+func TestHTTPStateManagerReadsOlderServers(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	f := &fakeStateServer{bodies: map[string][]byte{
+		"/defs": []byte(`{}`),
+		"/gdm":  []byte(`{"Deployments": []}`),
+	}}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+	hsm, err := NewHTTPStateManager(srv.URL)
+	require.NoError(err)
+
+	state, err := hsm.ReadState()
+	require.NoError(err)
+	assert.Len(state.Tombstones, 0)
+	assert.Len(state.Rollbacks, 0)
+}
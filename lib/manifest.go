@@ -2,7 +2,6 @@ package sous
 
 import (
 	"fmt"
-	"path/filepath"
 
 	"github.com/pkg/errors"
 )
@@ -31,6 +30,10 @@ type (
 		Kind ManifestKind `validate:"nonzero"`
 		// Deployments is a map of cluster names to DeploymentSpecs
 		Deployments DeploySpecs `validate:"keys=nonempty,values=nonzero"`
+		// AutoRollback, if true, makes Sous redeploy the previous version of
+		// this software in any cluster where a new version fails to roll out.
+		// Clusters can also enable this for all their deployments.
+		AutoRollback bool `yaml:",omitempty"`
 	}
 )
 
@@ -49,7 +52,7 @@ func (m *Manifest) SetID(mid ManifestID) {
 // Clone returns a deep copy of this Manifest.
 func (m Manifest) Clone() (c *Manifest) {
 	owners := make([]string, len(m.Owners))
-	copy(m.Owners, owners)
+	copy(owners, m.Owners)
 	deployments := make(DeploySpecs, len(m.Deployments))
 	for k, v := range m.Deployments {
 		deployments[k] = v.Clone()
@@ -59,9 +62,10 @@ func (m Manifest) Clone() (c *Manifest) {
 	return &m
 }
 
-// FileLocation returns the path that the manifest should be saved to.
+// FileLocation returns the path that the manifest should be saved to,
+// relative to the root of the state.
 func (m *Manifest) FileLocation() string {
-	return filepath.Join(string(m.Source.Repo), string(m.Source.Dir))
+	return m.ID().FileLocation()
 }
 
 // Diff returns true and a list of differences if m and o are not equal.
@@ -79,6 +83,9 @@ func (m *Manifest) Diff(o *Manifest) (bool, []string) {
 	if m.Kind != o.Kind {
 		diff("kind; this: %q; other: %q", m.Kind, o.Kind)
 	}
+	if m.AutoRollback != o.AutoRollback {
+		diff("auto rollback; this: %t; other: %t", m.AutoRollback, o.AutoRollback)
+	}
 	if len(m.Owners) != len(o.Owners) {
 		diff("number of owners; this: %d; other: %d", len(m.Owners), len(o.Owners))
 	} else {
@@ -111,8 +118,26 @@ func (m *Manifest) Equal(o *Manifest) bool {
 func (m *Manifest) Validate() []Flaw {
 	var flaws []Flaw
 
-	for _, depSpec := range m.Deployments {
-		flaws = append(flaws, depSpec.Validate()...)
+	if global, ok := m.Global(); ok {
+		for _, f := range global.Validate() {
+			// The Global DeploySpec needn't be complete by itself.
+			if _, missing := f.(*MissingResourceFlaw); !missing {
+				flaws = append(flaws, f)
+			}
+		}
+	}
+	for _, cluster := range m.ClusterNames() {
+		depSpec := m.ClusterSpec(cluster)
+		for _, f := range m.ownFlaws(cluster, depSpec.Validate()) {
+			f.AddContext("cluster name", cluster)
+			flaws = append(flaws, f)
+		}
+		if m.Kind.isScheduled() && depSpec.Schedule == "" {
+			flaws = append(flaws, unrepairableFlaw("manifest %q is %s, but has no schedule for cluster %q", m.ID(), m.Kind, cluster))
+		}
+		if !m.Kind.isScheduled() && depSpec.Schedule != "" {
+			flaws = append(flaws, unrepairableFlaw("manifest %q is %s, so cannot have a schedule for cluster %q", m.ID(), m.Kind, cluster))
+		}
 	}
 
 	if m.Kind == "" {
@@ -131,6 +156,21 @@ func (m *Manifest) Validate() []Flaw {
 	return flaws
 }
 
+// ownFlaws makes missing resources found in the merged DeploySpec of cluster
+// be repaired in the cluster's own DeploySpec.
+func (m *Manifest) ownFlaws(cluster string, flaws []Flaw) []Flaw {
+	for _, f := range flaws {
+		mrf, is := f.(*MissingResourceFlaw)
+		if !is {
+			continue
+		}
+		mrf.Resources = m.Deployments[cluster].Resources
+		mrf.ClusterName = cluster
+		mrf.specs = m.Deployments
+	}
+	return flaws
+}
+
 // Repair implements Flawed for State
 func (m *Manifest) Repair(fs []Flaw) error {
 	return errors.Errorf("Can't do nuffin with flaws yet")
@@ -0,0 +1,84 @@
+package sous
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+type (
+	// A ManifestHistorian knows how manifests have changed.
+	ManifestHistorian interface {
+		// ManifestHistory returns the changes to the manifest with the given
+		// ID, most recent first.
+		ManifestHistory(ManifestID) ([]ManifestChange, error)
+	}
+
+	// A ManifestChange is a change to a manifest, recorded as a commit to
+	// the state.
+	ManifestChange struct {
+		// Revision identifies the commit.
+		Revision string
+		// Author is who made the commit.
+		Author string
+		// Time is when the commit was made.
+		Time time.Time
+		// Message is the summary line of the commit's message.
+		Message string
+		// Manifest is the manifest as the commit left it, or nil if the commit
+		// deleted it.
+		Manifest *Manifest `json:",omitempty"`
+		// Diffs lists how the manifest differs from the version before the
+		// commit, in the form returned by Manifest.Diff, with the prior
+		// version as "this".
+		Diffs []string
+	}
+)
+
+// ManifestChanges builds the changes between successive versions of a
+// manifest. Each of versions holds the details of a commit, and the manifest
+// as that commit left it. They should be ordered most recent first, as
+// changes are returned.
+func ManifestChanges(versions []ManifestChange) []ManifestChange {
+	changes := make([]ManifestChange, len(versions))
+	for i, c := range versions {
+		var prior *Manifest
+		if i+1 < len(versions) {
+			prior = versions[i+1].Manifest
+		}
+		switch {
+		default:
+			_, c.Diffs = prior.Diff(c.Manifest)
+		case prior == nil && c.Manifest == nil:
+			c.Diffs = nil
+		case prior == nil:
+			c.Diffs = []string{"created"}
+		case c.Manifest == nil:
+			c.Diffs = []string{"deleted"}
+		}
+		changes[i] = c
+	}
+	return changes
+}
+
+// DumpManifestHistory prints changes to a manifest, with what each changed.
+func DumpManifestHistory(io io.Writer, changes []ManifestChange) {
+	w := &tabwriter.Writer{}
+	w.Init(io, 2, 4, 2, ' ', 0)
+
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", shortRevision(c.Revision), c.Time.Format(time.RFC3339), c.Author, c.Message)
+		for _, d := range c.Diffs {
+			fmt.Fprintf(w, "  %s\n", d)
+		}
+	}
+	w.Flush()
+}
+
+func shortRevision(rev string) string {
+	if len(rev) > 8 {
+		return rev[:8]
+	}
+	return rev
+}
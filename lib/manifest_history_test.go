@@ -0,0 +1,31 @@
+package sous
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+)
+
+func TestManifestChanges(t *testing.T) {
+	assert := assert.New(t)
+
+	v1 := &Manifest{Source: SourceLocation{Repo: "gh"}, Kind: ManifestKindService}
+	v2 := v1.Clone()
+	v2.Kind = ManifestKindWorker
+
+	changes := ManifestChanges([]ManifestChange{
+		{Revision: "deleted"},
+		{Revision: "changed", Manifest: v2},
+		{Revision: "created", Manifest: v1},
+	})
+	assert.Len(changes, 3)
+	assert.Equal([]string{"deleted"}, changes[0].Diffs)
+	assert.Equal([]string{`kind; this: "http-service"; other: "worker"`}, changes[1].Diffs)
+	assert.Equal([]string{"created"}, changes[2].Diffs)
+
+	buf := &bytes.Buffer{}
+	DumpManifestHistory(buf, changes)
+	assert.Contains(buf.String(), "changed")
+	assert.Contains(buf.String(), `  kind; this: "http-service"; other: "worker"`)
+}
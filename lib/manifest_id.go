@@ -3,6 +3,7 @@ package sous
 import (
 	"bytes"
 	"fmt"
+	"path"
 )
 
 // ManifestID identifies a manifest by its SourceLocation and optional Flavor.
@@ -43,6 +44,12 @@ func (mid ManifestID) String() string {
 	return mid.Source.String() + f
 }
 
+// FileLocation returns the path that the manifest with this ID is saved to,
+// relative to the root of the state.
+func (mid ManifestID) FileLocation() string {
+	return path.Join("manifests", mid.String()+".yaml")
+}
+
 // MarshalText implements encoding.TextMarshaler.
 // This is important for serialising maps that use ManifestID as a key.
 func (mid ManifestID) MarshalText() ([]byte, error) {
@@ -22,26 +22,38 @@ func (s *State) Deployments() (Deployments, error) {
 	}
 	for _, id := range ds.Keys() {
 		d, _ := ds.Get(id)
-		for name, val := range d.Cluster.Env {
-			if _, ok := d.Env[name]; ok {
-				continue
-			}
-			d.Env[name] = string(val)
-		}
-		cluster, ok := s.Defs.Clusters[d.ClusterName]
-		if !ok {
-			return ds, errors.Errorf("cluster %q is not described in defs.yaml (but specified in manifest %q)",
-				d.ClusterName, id.ManifestID)
-		}
-		if cluster == nil {
-			return ds, errors.Errorf("cluster %q is nil, check defs.yaml", d.ClusterName)
+		if err := s.bindCluster(d); err != nil {
+			return ds, err
 		}
-		d.Cluster = cluster
 	}
 	return ds, nil
 }
 
-// Manifests creates manifests from deployments.
+// bindCluster points d at its cluster, and fills in the cluster's default
+// env vars that d doesn't set.
+func (s *State) bindCluster(d *Deployment) error {
+	for name, val := range d.Cluster.Env {
+		if _, ok := d.Env[name]; ok {
+			continue
+		}
+		d.Env[name] = string(val)
+	}
+	cluster, ok := s.Defs.Clusters[d.ClusterName]
+	if !ok {
+		return errors.Errorf("cluster %q is not described in defs.yaml (but specified in manifest %q)",
+			d.ClusterName, d.ManifestID())
+	}
+	if cluster == nil {
+		return errors.Errorf("cluster %q is nil, check defs.yaml", d.ClusterName)
+	}
+	d.Cluster = cluster
+	return nil
+}
+
+// Manifests creates manifests from deployments. The manifests have no Global
+// DeploySpec: each cluster's DeploySpec has all of its deployment's values,
+// apart from its cluster's default env vars. To keep the Global DeploySpecs
+// of manifests rebuilt from deployments, use State.UpdateManifests.
 func (ds Deployments) Manifests(defs Defs) (Manifests, error) {
 	ms := NewManifests()
 	for _, k := range ds.Keys() {
@@ -82,22 +94,53 @@ func (ds Deployments) Manifests(defs Defs) (Manifests, error) {
 		}
 		m.Deployments[d.ClusterName] = spec
 		m.Kind = d.Kind
+		m.AutoRollback = d.AutoRollback
 		ms.Set(mid, m)
 	}
 	return ms, nil
 }
 
+// UpdateManifests replaces the state's manifests with those built from ds,
+// keeping the shape of the manifests the state already has: values which an
+// existing manifest's Global DeploySpec supplies are still inherited from it.
+// It returns an error, and leaves the state alone, if some deployment zeroes
+// or leaves out a value its manifest's Global DeploySpec sets, which it
+// would otherwise silently inherit.
+func (s *State) UpdateManifests(ds Deployments) error {
+	ms, err := ds.Manifests(s.Defs)
+	if err != nil {
+		return err
+	}
+	if s.Manifests.m == nil {
+		s.Manifests = ms
+		return nil
+	}
+	for mid, m := range ms.Snapshot() {
+		existing, ok := s.Manifests.Get(mid)
+		if !ok {
+			continue
+		}
+		shaped, flaws := m.shapeLike(existing)
+		if len(flaws) > 0 {
+			return errors.Errorf("manifest %q: %v", mid, flaws)
+		}
+		ms.Set(mid, shaped)
+	}
+	s.Manifests = ms
+	return nil
+}
+
 // DeploymentsFromManifest returns all deployments described by a single
 // manifest, in terms of the wider state (i.e. global and cluster definitions
 // and configuration).
 func (s *State) DeploymentsFromManifest(m *Manifest) (Deployments, error) {
 	ds := NewDeployments()
 	var inherit []DeploySpec
-	if global, ok := m.Deployments["Global"]; ok {
+	if global, ok := m.Global(); ok {
 		inherit = append(inherit, global)
-		delete(m.Deployments, "Global")
 	}
-	for clusterName, spec := range m.Deployments {
+	for _, clusterName := range m.ClusterNames() {
+		spec := m.Deployments[clusterName]
 		cluster, ok := s.Defs.Clusters[clusterName]
 		if !ok {
 			return ds, errors.Errorf("cluster %q not described in defs.yaml", clusterName)
@@ -127,6 +170,7 @@ func BuildDeployment(s *State, m *Manifest, nick string, spec DeploySpec, inheri
 		Owners:       ownMap,
 		Kind:         m.Kind,
 		SourceID:     m.Source.SourceID(ds.Version),
+		AutoRollback: m.AutoRollback,
 	}, nil
 }
 
@@ -0,0 +1,70 @@
+package sous
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/opentable/sous/util/metrics"
+)
+
+var (
+	resolveDurations = metrics.Default.NewHistogram("sous_resolve_duration_seconds",
+		"How long each automatic resolve cycle took, by outcome: success or failure.",
+		nil, "outcome")
+
+	rectificationErrors = metrics.Default.NewCounter("sous_rectification_errors_total",
+		"Errors rectifying deployments, by type of change and cluster.",
+		"type", "cluster")
+
+	deploymentCounts = metrics.Default.NewGauge("sous_deployments",
+		"Deployments per cluster at the last resolve, as intended in the GDM or actually running (ADS).",
+		"cluster", "source")
+)
+
+func observeResolve(start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	resolveDurations.ObserveSince(start, outcome)
+}
+
+func countRectificationError(err RectificationError) {
+	var kind string
+	switch err.(type) {
+	default:
+		kind = fmt.Sprintf("%T", err)
+	case *CreateError:
+		kind = "create"
+	case *DeleteError:
+		kind = "delete"
+	case *ChangeError:
+		kind = "change"
+	case *RollbackError:
+		kind = "rollback"
+	}
+	d := err.IntendedDeployment()
+	if d == nil {
+		d = err.ExistingDeployment()
+	}
+	cluster := ""
+	if d != nil {
+		cluster = d.ClusterName
+	}
+	rectificationErrors.Inc(kind, cluster)
+}
+
+func countDeployments(clusters Clusters, intended, actual Deployments) {
+	for source, ds := range map[string]Deployments{"gdm": intended, "ads": actual} {
+		counts := map[string]int{}
+		for name := range clusters {
+			counts[name] = 0
+		}
+		for _, d := range ds.Snapshot() {
+			counts[d.ClusterName]++
+		}
+		for name, n := range counts {
+			deploymentCounts.Set(float64(n), name, source)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package sous
 
+import "sort"
+
 // OwnerSet collects the names of the owners of a deployment.
 type OwnerSet map[string]struct{}
 
@@ -36,10 +38,12 @@ func (os OwnerSet) Equal(o OwnerSet) bool {
 	return true
 }
 
+// Slice returns the owners in this set, sorted by name.
 func (os OwnerSet) Slice() []string {
 	slice := make([]string, 0, len(os))
-	for owner, _ := range os {
+	for owner := range os {
 		slice = append(slice, owner)
 	}
+	sort.Strings(slice)
 	return slice
 }
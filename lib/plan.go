@@ -0,0 +1,255 @@
+package sous
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/opentable/sous/util/yaml"
+	"github.com/pkg/errors"
+)
+
+type (
+	// A Plan is a record of the changes a Resolver would make to bring the
+	// actual deployments in line with the intended ones. It can be reviewed,
+	// and then applied with Resolver.Apply.
+	Plan struct {
+		// Creates are deployments that would be created.
+		Creates []*Deployment
+		// Deletes are deployments that would be deleted.
+		Deletes []*Deployment
+		// Modifies are deployments that would be changed.
+		Modifies []*PlannedModification
+		// Blocked are changes that won't be made because their clusters are
+		// frozen.
+		Blocked []ResolveDiff `json:",omitempty" yaml:",omitempty"`
+	}
+
+	// A PlannedModification is a deployment change within a Plan.
+	PlannedModification struct {
+		// Prior is the deployment as it was running when the plan was made.
+		Prior *Deployment
+		// Post is the deployment as it will be after the plan is applied.
+		Post *Deployment
+		// Diffs describes the differences between Prior and Post.
+		Diffs []string
+	}
+
+	// StalePlanError is returned by Resolver.Apply if the running deployments
+	// have changed since the plan was made.
+	StalePlanError struct {
+		Changes []string
+	}
+
+	// readOnlyTombstones lets planning see tombstones without touching them.
+	readOnlyTombstones struct {
+		TombstoneKeeper
+	}
+
+	// readOnlyRollbacks lets planning see rollbacks without touching them.
+	readOnlyRollbacks struct {
+		RollbackKeeper
+	}
+)
+
+func (e *StalePlanError) Error() string {
+	return fmt.Sprintf("deployments have changed since the plan was made:\n  %s", strings.Join(e.Changes, "\n  "))
+}
+
+func (readOnlyTombstones) WriteTombstones(Tombstones) error { return nil }
+
+func (readOnlyRollbacks) WriteRollbacks(Rollbacks) error { return nil }
+
+// Empty returns true if this plan makes no changes.
+func (p *Plan) Empty() bool {
+	return len(p.Creates) == 0 && len(p.Deletes) == 0 && len(p.Modifies) == 0
+}
+
+// Plan computes the changes that Resolve would make, without making them.
+func (r *Resolver) Plan(intended Deployments, clusters Clusters) (*Plan, error) {
+	pr := *r
+	if pr.Tombstones != nil {
+		pr.Tombstones = readOnlyTombstones{r.Tombstones}
+	}
+	if pr.Rollbacks != nil {
+		pr.Rollbacks = readOnlyRollbacks{r.Rollbacks}
+	}
+	diffs, tombErrs, err := pr.diff(intended, clusters)
+	if err != nil {
+		return nil, err
+	}
+	rec := NewResolveRecord(r.ResolveFilter)
+	diffs = pr.guardFreezes(diffs, rec)
+
+	ds := diffs.collect()
+	if err := <-tombErrs; err != nil {
+		return nil, err
+	}
+
+	p := &Plan{}
+	for _, d := range ds.New.Snapshot() {
+		p.Creates = append(p.Creates, d)
+	}
+	for _, d := range ds.Gone.Snapshot() {
+		p.Deletes = append(p.Deletes, d)
+	}
+	for _, pair := range ds.Changed {
+		_, changes := pair.Prior.Diff(pair.Post)
+		p.Modifies = append(p.Modifies, &PlannedModification{Prior: pair.Prior, Post: pair.Post, Diffs: changes})
+	}
+
+	p.Blocked = rec.Blocked
+
+	sort.Sort(deploymentsByID(p.Creates))
+	sort.Sort(deploymentsByID(p.Deletes))
+	sort.Sort(modificationsByID(p.Modifies))
+	return p, nil
+}
+
+type (
+	deploymentsByID   []*Deployment
+	modificationsByID []*PlannedModification
+)
+
+func (ds deploymentsByID) Len() int           { return len(ds) }
+func (ds deploymentsByID) Swap(i, j int)      { ds[i], ds[j] = ds[j], ds[i] }
+func (ds deploymentsByID) Less(i, j int) bool { return deployIDLess(ds[i].ID(), ds[j].ID()) }
+
+func (ms modificationsByID) Len() int      { return len(ms) }
+func (ms modificationsByID) Swap(i, j int) { ms[i], ms[j] = ms[j], ms[i] }
+func (ms modificationsByID) Less(i, j int) bool {
+	return deployIDLess(ms[i].Post.ID(), ms[j].Post.ID())
+}
+
+func deployIDLess(a, b DeployID) bool {
+	if a.Cluster != b.Cluster {
+		return a.Cluster < b.Cluster
+	}
+	return a.ManifestID.String() < b.ManifestID.String()
+}
+
+// Apply makes exactly the changes recorded in a plan. Before changing
+// anything, it checks that each deployment the plan would touch is still
+// running as it was when the plan was made, and returns a *StalePlanError if
+// not. Changes to clusters which have been frozen since the plan was made are
+// blocked.
+func (r *Resolver) Apply(p *Plan, clusters Clusters) error {
+	if err := p.bind(clusters); err != nil {
+		return err
+	}
+
+	touched := make(Clusters)
+	for _, d := range p.deployments() {
+		touched[d.ClusterName] = clusters[d.ClusterName]
+	}
+	ads, err := r.Deployer.RunningDeployments(touched)
+	if err != nil {
+		return err
+	}
+	if err := p.check(ads); err != nil {
+		return err
+	}
+
+	diffs := NewDiffChans(len(p.Creates) + len(p.Deletes) + len(p.Modifies))
+	for _, d := range p.Creates {
+		diffs.Created <- d
+	}
+	for _, d := range p.Deletes {
+		diffs.Deleted <- d
+	}
+	for _, m := range p.Modifies {
+		diffs.Modified <- &DeploymentPair{name: m.Post.ID(), Prior: m.Prior, Post: m.Post}
+	}
+	diffs.Close()
+
+	rec := NewResolveRecord(r.ResolveFilter)
+	err = r.recordRollbacks(foldErrors(r.rectify(rec.watch(r.guardFreezes(diffs, rec)))))
+	r.recordResolve(rec, err)
+	return err
+}
+
+func (p *Plan) deployments() []*Deployment {
+	ds := append([]*Deployment{}, p.Creates...)
+	ds = append(ds, p.Deletes...)
+	for _, m := range p.Modifies {
+		ds = append(ds, m.Prior, m.Post)
+	}
+	return ds
+}
+
+// bind points the deployments in this plan at the current definitions of
+// their clusters.
+func (p *Plan) bind(clusters Clusters) error {
+	for _, d := range p.deployments() {
+		c, ok := clusters[d.ClusterName]
+		if !ok {
+			return errors.Errorf("plan refers to unknown cluster %q", d.ClusterName)
+		}
+		d.Cluster = c
+	}
+	return nil
+}
+
+func (p *Plan) check(ads Deployments) error {
+	var changes []string
+	for _, d := range p.Creates {
+		if _, running := ads.Get(d.ID()); running {
+			changes = append(changes, fmt.Sprintf("%s has been created", d))
+		}
+	}
+	running := func(prior *Deployment) {
+		current, ok := ads.Get(prior.ID())
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s is no longer running", prior))
+			return
+		}
+		if different, ds := prior.Diff(current); different {
+			changes = append(changes, fmt.Sprintf("%s has changed: %s", prior, strings.Join(ds, "; ")))
+		}
+	}
+	for _, d := range p.Deletes {
+		running(d)
+	}
+	for _, m := range p.Modifies {
+		running(m.Prior)
+	}
+	if len(changes) > 0 {
+		return &StalePlanError{Changes: changes}
+	}
+	return nil
+}
+
+// WritePlan serializes a plan, as YAML if asYAML is true, and otherwise as
+// JSON.
+func WritePlan(w io.Writer, p *Plan, asYAML bool) error {
+	var b []byte
+	var err error
+	if asYAML {
+		b, err = yaml.Marshal(p)
+	} else {
+		b, err = json.MarshalIndent(p, "", "  ")
+	}
+	if err != nil {
+		return errors.Wrap(err, "serializing plan")
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// ReadPlan deserializes a plan written by WritePlan.
+func ReadPlan(r io.Reader, asYAML bool) (*Plan, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &Plan{}
+	if asYAML {
+		err = yaml.Unmarshal(b, p)
+	} else {
+		err = json.Unmarshal(b, p)
+	}
+	return p, errors.Wrap(err, "reading plan")
+}
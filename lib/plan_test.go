@@ -0,0 +1,109 @@
+package sous
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+func planFixtures() (*Resolver, *recordingDeployer, Deployments, Clusters) {
+	clusters := Clusters{
+		"a": &Cluster{Name: "a", DeletePolicy: DeletePolicy{Mode: DeleteImmediately}},
+	}
+	dep := func(sid string) *Deployment {
+		return &Deployment{
+			SourceID:     MustParseSourceID(sid),
+			ClusterName:  "a",
+			Cluster:      clusters["a"],
+			DeployConfig: DeployConfig{NumInstances: 1},
+		}
+	}
+
+	rd := newRecordingDeployer()
+	rd.running.Add(dep("github.com/ot/one,1.0.0"))
+	rd.running.Add(dep("github.com/ot/two,1.0.0"))
+
+	intended := NewDeployments(
+		dep("github.com/ot/one,2.0.0"),
+		dep("github.com/ot/three,1.0.0"),
+	)
+	r := NewResolver(rd, NewDummyRegistry(), &ResolveFilter{})
+	return r, rd, intended, clusters
+}
+
+func TestPlan(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r, rd, intended, clusters := planFixtures()
+
+	p, err := r.Plan(intended, clusters)
+	require.NoError(err)
+
+	if assert.Len(p.Creates, 1) {
+		assert.Equal("github.com/ot/three", p.Creates[0].SourceID.Location.Repo)
+	}
+	if assert.Len(p.Deletes, 1) {
+		assert.Equal("github.com/ot/two", p.Deletes[0].SourceID.Location.Repo)
+	}
+	if assert.Len(p.Modifies, 1) {
+		m := p.Modifies[0]
+		assert.Equal("1.0.0", m.Prior.SourceID.Version.String())
+		assert.Equal("2.0.0", m.Post.SourceID.Version.String())
+		assert.Len(m.Diffs, 1)
+	}
+
+	assert.Empty(rd.created)
+	assert.Empty(rd.deleted)
+	assert.Empty(rd.modified)
+}
+
+func TestApplyRoundTrip(t *testing.T) {
+	require := require.New(t)
+
+	for _, asYAML := range []bool{false, true} {
+		assert := assert.New(t)
+		r, rd, intended, clusters := planFixtures()
+
+		p, err := r.Plan(intended, clusters)
+		require.NoError(err)
+
+		buf := &bytes.Buffer{}
+		require.NoError(WritePlan(buf, p, asYAML))
+		read, err := ReadPlan(buf, asYAML)
+		require.NoError(err)
+
+		require.NoError(r.Apply(read, clusters))
+		assert.Len(rd.created, 1)
+		assert.Len(rd.deleted, 1)
+		assert.Len(rd.modified, 1)
+	}
+}
+
+func TestApplyStalePlan(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r, rd, intended, clusters := planFixtures()
+
+	p, err := r.Plan(intended, clusters)
+	require.NoError(err)
+
+	// Someone else has deployed in the meantime.
+	rd.running = NewDeployments(&Deployment{
+		SourceID:     MustParseSourceID("github.com/ot/one,1.5.0"),
+		ClusterName:  "a",
+		Cluster:      clusters["a"],
+		DeployConfig: DeployConfig{NumInstances: 1},
+	})
+
+	err = r.Apply(p, clusters)
+	if assert.IsType(&StalePlanError{}, err) {
+		assert.Len(err.(*StalePlanError).Changes, 2)
+	}
+	assert.Empty(rd.created)
+	assert.Empty(rd.deleted)
+	assert.Empty(rd.modified)
+}
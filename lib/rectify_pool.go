@@ -0,0 +1,124 @@
+package sous
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+type (
+	// rectifyPool runs rectifications on a bounded number of workers per
+	// cluster. Each deployment is always handed to the same worker, so changes
+	// to any one deployment are made in order.
+	rectifyPool struct {
+		deployer Deployer
+		errs     chan<- RectificationError
+		size     int
+		sync.Mutex
+		workers map[rectifyWorkerKey]*rectifyWorker
+		wg      sync.WaitGroup
+	}
+
+	rectifyWorkerKey struct {
+		cluster string
+		n       uint32
+	}
+
+	rectifyWorker struct {
+		created, deleted chan *Deployment
+		modified         chan *DeploymentPair
+	}
+)
+
+// DefaultConcurrency is the number of rectification workers used for clusters
+// which don't set Concurrency.
+const DefaultConcurrency = 1
+
+// concurrency returns the number of rectification workers for a cluster.
+func concurrency(c *Cluster) uint32 {
+	if c == nil || c.Concurrency < 1 {
+		return DefaultConcurrency
+	}
+	return uint32(c.Concurrency)
+}
+
+// validateRateLimit checks the cluster's RateLimit, which only the Singularity
+// deployer observes.
+func (c *Cluster) validateRateLimit() []Flaw {
+	switch {
+	case c.RateLimit < 0:
+		return []Flaw{unrepairableFlaw("cluster %q has a negative RateLimit", c.Name)}
+	case c.RateLimit > 0 && clusterKind(c) != DefaultClusterKind:
+		return []Flaw{unrepairableFlaw("cluster %q sets a RateLimit, which only %s clusters support", c.Name, DefaultClusterKind)}
+	}
+	return nil
+}
+
+func newRectifyPool(d Deployer, errs chan<- RectificationError, size int) *rectifyPool {
+	return &rectifyPool{
+		deployer: d,
+		errs:     errs,
+		size:     size,
+		workers:  make(map[rectifyWorkerKey]*rectifyWorker),
+	}
+}
+
+// worker returns the worker responsible for a deployment, starting it if
+// need be.
+func (p *rectifyPool) worker(d *Deployment) *rectifyWorker {
+	id := d.ID()
+	h := fnv.New32a()
+	h.Write([]byte(id.ManifestID.String()))
+	key := rectifyWorkerKey{cluster: id.Cluster, n: h.Sum32() % concurrency(d.Cluster)}
+
+	p.Lock()
+	defer p.Unlock()
+	if w, ok := p.workers[key]; ok {
+		return w
+	}
+	w := &rectifyWorker{
+		created:  make(chan *Deployment, p.size),
+		deleted:  make(chan *Deployment, p.size),
+		modified: make(chan *DeploymentPair, p.size),
+	}
+	p.workers[key] = w
+	p.wg.Add(3)
+	go func() { p.deployer.RectifyCreates(w.created, p.errs); p.wg.Done() }()
+	go func() { p.deployer.RectifyDeletes(w.deleted, p.errs); p.wg.Done() }()
+	go func() { p.deployer.RectifyModifies(w.modified, p.errs); p.wg.Done() }()
+	return w
+}
+
+// run distributes the diffs to workers, and returns once all of them have
+// been rectified.
+func (p *rectifyPool) run(dcs DiffChans) {
+	dispatch := sync.WaitGroup{}
+	dispatch.Add(3)
+	go func() {
+		for d := range dcs.Created {
+			p.worker(d).created <- d
+		}
+		dispatch.Done()
+	}()
+	go func() {
+		for d := range dcs.Deleted {
+			p.worker(d).deleted <- d
+		}
+		dispatch.Done()
+	}()
+	go func() {
+		for pair := range dcs.Modified {
+			p.worker(pair.Post).modified <- pair
+		}
+		dispatch.Done()
+	}()
+	dispatch.Wait()
+
+	p.Lock()
+	for _, w := range p.workers {
+		close(w.created)
+		close(w.deleted)
+		close(w.modified)
+	}
+	p.Unlock()
+	p.wg.Wait()
+}
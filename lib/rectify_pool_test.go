@@ -0,0 +1,83 @@
+package sous
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+)
+
+type concurrencyDeployer struct {
+	DummyDeployer
+	sync.Mutex
+	running, most int
+	created       []DeployID
+}
+
+func (cd *concurrencyDeployer) RectifyCreates(dc <-chan *Deployment, errs chan<- RectificationError) {
+	for d := range dc {
+		cd.Lock()
+		cd.running++
+		if cd.running > cd.most {
+			cd.most = cd.running
+		}
+		cd.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		cd.Lock()
+		cd.running--
+		cd.created = append(cd.created, d.ID())
+		cd.Unlock()
+	}
+}
+
+func TestRectifyPoolConcurrency(t *testing.T) {
+	assert := assert.New(t)
+
+	cluster := &Cluster{Name: "a", Concurrency: 3}
+	dcs := NewDiffChans(12)
+	for i := 0; i < 12; i++ {
+		dcs.Created <- &Deployment{
+			SourceID:    MustParseSourceID(fmt.Sprintf("github.com/ot/%d,1.0.0", i)),
+			ClusterName: "a",
+			Cluster:     cluster,
+		}
+	}
+	dcs.Close()
+
+	cd := &concurrencyDeployer{}
+	r := &Resolver{Deployer: cd}
+	for range r.rectify(dcs) {
+	}
+
+	assert.Len(cd.created, 12)
+	assert.True(cd.most > 1, "expected concurrent rectification, got %d", cd.most)
+	assert.True(cd.most <= 3, "expected at most 3 concurrent rectifications, got %d", cd.most)
+}
+
+func TestRectifyPoolWorkerPerDeployment(t *testing.T) {
+	assert := assert.New(t)
+
+	cluster := &Cluster{Name: "a", Concurrency: 4}
+	p := newRectifyPool(&DummyDeployer{}, make(chan RectificationError), 0)
+	d := &Deployment{SourceID: MustParseSourceID("github.com/ot/one,1.0.0"), ClusterName: "a", Cluster: cluster}
+	later := d.Clone()
+	later.SourceID = MustParseSourceID("github.com/ot/one,2.0.0")
+
+	assert.True(p.worker(d) == p.worker(later))
+	assert.Equal(uint32(1), concurrency(&Cluster{}))
+}
+
+func TestClusterValidateRateLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Empty((&Cluster{Name: "a"}).validateRateLimit())
+	assert.Empty((&Cluster{Name: "a", RateLimit: 2}).validateRateLimit())
+	assert.Empty((&Cluster{Name: "a", Kind: "singularity", RateLimit: 0.5}).validateRateLimit())
+	assert.Len((&Cluster{Name: "a", RateLimit: -1}).validateRateLimit(), 1)
+	assert.Len((&Cluster{Name: "a", Kind: "kubernetes", RateLimit: 2}).validateRateLimit(), 1)
+	assert.Empty((&Cluster{Name: "a", Kind: "kubernetes"}).validateRateLimit())
+}
@@ -0,0 +1,139 @@
+package sous
+
+import "sort"
+
+type (
+	// ResourceLimits maps resource names to the limits on them in a cluster.
+	ResourceLimits map[string]ResourceLimit
+
+	// A ResourceLimit bounds the values a resource may take in a cluster, and
+	// supplies a value for deployments which don't set one. Min and Max only
+	// apply to numeric resources, and each may be left empty.
+	ResourceLimit struct {
+		Min, Max string `yaml:",omitempty"`
+		// Default is used in place of a missing value.
+		Default string `yaml:",omitempty"`
+	}
+)
+
+// Get returns the definition of the resource called name.
+func (rds ResDefs) Get(name string) (ResDef, bool) {
+	for _, rd := range rds {
+		if rd.Name == name {
+			return rd, true
+		}
+	}
+	return ResDef{}, false
+}
+
+// Validate checks that each definition has a name and a known type, and
+// that limits set for the resource in each cluster are of that type.
+func (rds ResDefs) Validate(clusters Clusters) []Flaw {
+	var flaws []Flaw
+	for _, rd := range rds {
+		if rd.Name == "" {
+			flaws = append(flaws, unrepairableFlaw("resource definition has no name"))
+		}
+		if err := rd.Type.Validate(); err != nil {
+			flaws = append(flaws, unrepairableFlaw("resource %q: %s", rd.Name, err))
+		}
+	}
+	for _, name := range clusters.names() {
+		for _, res := range clusters[name].Resources.names() {
+			rd, ok := rds.Get(res)
+			if !ok {
+				flaws = append(flaws, unrepairableFlaw("cluster %q limits resource %q, which isn't defined", name, res))
+				continue
+			}
+			limit := clusters[name].Resources[res]
+			for _, v := range []string{limit.Min, limit.Max, limit.Default} {
+				if v == "" {
+					continue
+				}
+				if _, err := rd.Type.parse(v); err != nil {
+					flaws = append(flaws, unrepairableFlaw("cluster %q limits on resource %q: %s", name, res, err))
+				}
+			}
+		}
+	}
+	return flaws
+}
+
+// check validates rezs, used in cluster, against these definitions and the
+// cluster's limits. Resources which aren't defined, have values of the wrong
+// type, or are outside the cluster's limits are unrepairable flaws. A defined
+// resource that is missing is a MissingResourceFlaw, which is repaired with
+// the cluster's default, if it has one.
+func (rds ResDefs) check(rezs Resources, clusterName string, cluster *Cluster) []Flaw {
+	if rezs == nil {
+		// DeployConfig.Validate already reports missing Resources.
+		return nil
+	}
+	var limits ResourceLimits
+	if cluster != nil {
+		limits = cluster.Resources
+	}
+	var flaws []Flaw
+	for _, rd := range rds {
+		if _, has := rezs[rd.Name]; !has && !isRequiredResource(rd.Name) {
+			// Required resources are already checked by Resources.Validate.
+			flaws = append(flaws, &MissingResourceFlaw{
+				Resources:   rezs,
+				ClusterName: clusterName,
+				Field:       rd.Name,
+				Default:     limits[rd.Name].Default,
+			})
+		}
+	}
+	for _, name := range rezs.names() {
+		value := rezs[name]
+		rd, ok := rds.Get(name)
+		if !ok {
+			flaws = append(flaws, unrepairableFlaw("resource %q in cluster %q isn't defined", name, clusterName))
+			continue
+		}
+		n, err := rd.Type.parse(value)
+		if err != nil {
+			flaws = append(flaws, unrepairableFlaw("resource %q in cluster %q: %s", name, clusterName, err))
+			continue
+		}
+		if !rd.Type.isNumeric() {
+			continue
+		}
+		limit := limits[name]
+		if min, err := rd.Type.parse(limit.Min); limit.Min != "" && err == nil && n < min {
+			flaws = append(flaws, unrepairableFlaw("resource %q in cluster %q is %s, less than the minimum of %s", name, clusterName, value, limit.Min))
+		}
+		if max, err := rd.Type.parse(limit.Max); limit.Max != "" && err == nil && n > max {
+			flaws = append(flaws, unrepairableFlaw("resource %q in cluster %q is %s, more than the maximum of %s", name, clusterName, value, limit.Max))
+		}
+	}
+	return flaws
+}
+
+func (r Resources) names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (rl ResourceLimits) names() []string {
+	names := make([]string, 0, len(rl))
+	for name := range rl {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (cs Clusters) names() []string {
+	names := make([]string, 0, len(cs))
+	for name := range cs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
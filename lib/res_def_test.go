@@ -0,0 +1,79 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/samsalisbury/semv"
+)
+
+func resourceTestState(rezs Resources) *State {
+	s := NewState()
+	s.Defs.Resources = ResDefs{
+		{Name: "cpus", Type: VarTypeFloat},
+		{Name: "memory", Type: VarTypeMemorySize},
+		{Name: "ports", Type: VarTypeInt},
+		{Name: "disk", Type: VarTypeInt},
+	}
+	s.Defs.Clusters = Clusters{
+		"test": &Cluster{
+			Name: "test",
+			Resources: ResourceLimits{
+				"cpus":   {Min: "0.5", Max: "4"},
+				"memory": {Default: "512"},
+				"disk":   {Default: "10"},
+			},
+		},
+	}
+	s.Manifests.Add(&Manifest{
+		Source: SourceLocation{Repo: "github.com/opentable/test"},
+		Kind:   ManifestKindService,
+		Deployments: DeploySpecs{
+			"test": {
+				Version:      semv.MustParse("1.0.0"),
+				DeployConfig: DeployConfig{Resources: rezs},
+			},
+		},
+	})
+	return s
+}
+
+func TestResDefsValid(t *testing.T) {
+	s := resourceTestState(Resources{"cpus": "1", "memory": "256", "ports": "1", "disk": "20"})
+	assert.Empty(t, s.Validate())
+}
+
+func TestResDefsRepairDefaults(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	rezs := Resources{"cpus": "1"}
+	s := resourceTestState(rezs)
+	flaws := s.Validate()
+	require.Len(flaws, 3)
+	for _, f := range flaws {
+		assert.IsType(&MissingResourceFlaw{}, f)
+	}
+
+	fs, es := RepairAll(flaws)
+	assert.Empty(fs)
+	assert.Empty(es)
+	assert.Equal(Resources{"cpus": "1", "memory": "512", "ports": "1", "disk": "10"}, rezs)
+}
+
+func TestResDefsUnrepairable(t *testing.T) {
+	assert := assert.New(t)
+
+	s := resourceTestState(Resources{"cpus": "0.25", "memory": "lots", "ports": "1", "disk": "10", "gpus": "1"})
+	flaws := s.Validate()
+	assert.Len(flaws, 3)
+	fs, es := RepairAll(flaws)
+	assert.Len(fs, 3)
+	assert.Len(es, 3)
+
+	s = resourceTestState(Resources{"cpus": "1", "memory": "1", "ports": "1", "disk": "10"})
+	s.Defs.Clusters["test"].Resources["gpus"] = ResourceLimit{Max: "2"}
+	s.Defs.Resources[3].Type = "bytes"
+	assert.Len(s.Validate(), 2)
+}
@@ -43,6 +43,17 @@ type (
 		Err         error
 	}
 
+	// RollbackError reports that a change failed to roll out, and that the
+	// prior deployment was redeployed in its place.
+	RollbackError struct {
+		Deployments *DeploymentPair
+		// Err is the error that caused the rollback.
+		Err error
+		// RollbackErr is the error from redeploying the prior deployment, if
+		// the rollback failed too.
+		RollbackErr error
+	}
+
 	// RectificationError is an interface that extends error with methods to get
 	// the deployments the preceeded and were intended when the error occurred
 	RectificationError interface {
@@ -109,3 +120,22 @@ func (e *ChangeError) ExistingDeployment() *Deployment {
 func (e *ChangeError) IntendedDeployment() *Deployment {
 	return e.Deployments.Post
 }
+
+func (e *RollbackError) Error() string {
+	if e.RollbackErr != nil {
+		return fmt.Sprintf("%v: Couldn't change %q to version %s, and rolling back to %s failed too: %v",
+			e.Err, e.Deployments.Post.ID().ManifestID, e.Deployments.Post.SourceID.Version, e.Deployments.Prior.SourceID.Version, e.RollbackErr)
+	}
+	return fmt.Sprintf("%v: Couldn't change %q to version %s, so rolled back to %s",
+		e.Err, e.Deployments.Post.ID().ManifestID, e.Deployments.Post.SourceID.Version, e.Deployments.Prior.SourceID.Version)
+}
+
+// ExistingDeployment returns the deployment that was rolled back to
+func (e *RollbackError) ExistingDeployment() *Deployment {
+	return e.Deployments.Prior
+}
+
+// IntendedDeployment returns the deployment that failed to roll out
+func (e *RollbackError) IntendedDeployment() *Deployment {
+	return e.Deployments.Post
+}
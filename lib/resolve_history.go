@@ -0,0 +1,151 @@
+package sous
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// A ResolveRecord describes a single resolve run: what it was asked to
+	// resolve, what it changed, and what went wrong.
+	ResolveRecord struct {
+		Started, Finished time.Time
+		// Filter is the filter the resolve was run with.
+		Filter ResolveFilter
+		// Diffs are the changes the resolve applied, in the order they were
+		// handed to the deployer.
+		Diffs []ResolveDiff
+		// Errors are the causes of any errors the resolve returned.
+		Errors []string
+		// Blocked are the changes the resolve didn't make because their
+		// clusters were frozen.
+		Blocked []ResolveDiff
+		// FreezeOverride names whoever overrode a freeze during the resolve,
+		// if anybody did.
+		FreezeOverride string
+
+		lock sync.Mutex
+	}
+
+	// A ResolveDiff is a single change applied during a resolve.
+	ResolveDiff struct {
+		// Kind is one of "create", "delete" or "modify".
+		Kind       string
+		Cluster    string
+		ManifestID string
+		// Version is the version created, deleted, or changed to.
+		Version string
+		// Changes describes a modification.
+		Changes []string `json:",omitempty" yaml:",omitempty"`
+		// Reason explains why a change was blocked.
+		Reason string `json:",omitempty" yaml:",omitempty"`
+	}
+
+	// A ResolveHistory stores ResolveRecords.
+	ResolveHistory interface {
+		// RecordResolve stores a record.
+		RecordResolve(*ResolveRecord) error
+		// ResolveRecords returns up to limit records, most recent first.
+		ResolveRecords(limit int) ([]*ResolveRecord, error)
+	}
+)
+
+// NewResolveRecord starts a record of a resolve run using filter.
+func NewResolveRecord(filter *ResolveFilter) *ResolveRecord {
+	rec := &ResolveRecord{Started: time.Now()}
+	if filter != nil {
+		rec.Filter = *filter
+	}
+	return rec
+}
+
+func newResolveDiff(kind string, d *Deployment, changes []string) ResolveDiff {
+	id := d.ID()
+	return ResolveDiff{
+		Kind:       kind,
+		Cluster:    id.Cluster,
+		ManifestID: id.ManifestID.String(),
+		Version:    d.SourceID.Version.String(),
+		Changes:    changes,
+	}
+}
+
+func (rec *ResolveRecord) add(kind string, d *Deployment, changes []string) {
+	rec.lock.Lock()
+	defer rec.lock.Unlock()
+	rec.Diffs = append(rec.Diffs, newResolveDiff(kind, d, changes))
+}
+
+func (rec *ResolveRecord) block(kind string, d *Deployment, changes []string, reason string) {
+	Log.Warn.Printf("Not going to %s %s in cluster %s, which is frozen: %s", kind, d.ID().ManifestID, d.ClusterName, reason)
+	rec.lock.Lock()
+	defer rec.lock.Unlock()
+	bd := newResolveDiff(kind, d, changes)
+	bd.Reason = reason
+	rec.Blocked = append(rec.Blocked, bd)
+}
+
+func (rec *ResolveRecord) override(who string) {
+	rec.lock.Lock()
+	defer rec.lock.Unlock()
+	rec.FreezeOverride = who
+}
+
+// watch returns a DiffChans that passes on everything from dcs, adding each
+// created, deleted and modified deployment to the record as it goes.
+func (rec *ResolveRecord) watch(dcs DiffChans) DiffChans {
+	watched := DiffChans{
+		Created:  make(chan *Deployment, cap(dcs.Created)),
+		Deleted:  make(chan *Deployment, cap(dcs.Deleted)),
+		Retained: dcs.Retained,
+		Modified: make(chan *DeploymentPair, cap(dcs.Modified)),
+	}
+	pass := func(kind string, in <-chan *Deployment, out chan<- *Deployment) {
+		for d := range in {
+			rec.add(kind, d, nil)
+			out <- d
+		}
+		close(out)
+	}
+	go pass("create", dcs.Created, watched.Created)
+	go pass("delete", dcs.Deleted, watched.Deleted)
+	go func() {
+		for pair := range dcs.Modified {
+			_, changes := pair.Prior.Diff(pair.Post)
+			rec.add("modify", pair.Post, changes)
+			watched.Modified <- pair
+		}
+		close(watched.Modified)
+	}()
+	return watched
+}
+
+// finish completes the record with the result of the resolve.
+func (rec *ResolveRecord) finish(err error) {
+	rec.Finished = time.Now()
+	if err == nil {
+		return
+	}
+	if re, ok := errors.Cause(err).(*ResolveErrors); ok {
+		for _, cause := range re.Causes {
+			rec.Errors = append(rec.Errors, cause.Error())
+		}
+		return
+	}
+	rec.Errors = []string{err.Error()}
+}
+
+// recordResolve finishes rec and stores it in the Resolver's History, if it
+// has one. Failing to store the record is logged, but doesn't fail the
+// resolve.
+func (r *Resolver) recordResolve(rec *ResolveRecord, err error) {
+	if r.History == nil {
+		return
+	}
+	rec.finish(err)
+	if err := r.History.RecordResolve(rec); err != nil {
+		Log.Warn.Printf("recording resolve: %v", err)
+	}
+}
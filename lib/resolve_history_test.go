@@ -0,0 +1,63 @@
+package sous
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+type memHistory struct {
+	recs []*ResolveRecord
+}
+
+func (m *memHistory) RecordResolve(rec *ResolveRecord) error {
+	m.recs = append(m.recs, rec)
+	return nil
+}
+
+func (m *memHistory) ResolveRecords(limit int) ([]*ResolveRecord, error) {
+	return m.recs, nil
+}
+
+func TestResolveRecordsHistory(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r, _, intended, clusters := planFixtures()
+	h := &memHistory{}
+	r.History = h
+
+	require.NoError(r.Resolve(intended, clusters))
+	require.Len(h.recs, 1)
+	rec := h.recs[0]
+
+	assert.False(rec.Finished.Before(rec.Started))
+	assert.Empty(rec.Errors)
+
+	var diffs []string
+	for _, d := range rec.Diffs {
+		diffs = append(diffs, fmt.Sprintf("%s %s %s", d.Kind, d.ManifestID, d.Version))
+	}
+	sort.Strings(diffs)
+	assert.Equal([]string{
+		"create github.com/ot/three 1.0.0",
+		"delete github.com/ot/two 1.0.0",
+		"modify github.com/ot/one 2.0.0",
+	}, diffs)
+}
+
+func TestResolveRecordFinishCollectsCauses(t *testing.T) {
+	assert := assert.New(t)
+
+	rec := NewResolveRecord(&ResolveFilter{Cluster: "a"})
+	rec.finish(&ResolveErrors{Causes: []error{fmt.Errorf("one"), fmt.Errorf("two")}})
+	assert.Equal([]string{"one", "two"}, rec.Errors)
+	assert.Equal("a", rec.Filter.Cluster)
+
+	rec = NewResolveRecord(nil)
+	rec.finish(fmt.Errorf("three"))
+	assert.Equal([]string{"three"}, rec.Errors)
+}
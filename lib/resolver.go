@@ -2,7 +2,6 @@ package sous
 
 import (
 	"fmt"
-	"sync"
 
 	"github.com/opentable/sous/util/firsterr"
 	"github.com/pkg/errors"
@@ -14,6 +13,18 @@ type (
 	Resolver struct {
 		Deployer Deployer
 		Registry Registry
+		// Tombstones, if set, keeps track of deployments waiting out a
+		// cluster's delete grace period. Without it, clusters with a "grace"
+		// DeletePolicy never delete anything.
+		Tombstones TombstoneKeeper
+		// Rollbacks, if set, records deployments that were rolled back, so
+		// that their bad versions aren't deployed again.
+		Rollbacks RollbackKeeper
+		// History, if set, keeps a record of each resolve.
+		History ResolveHistory
+		// FreezeOverride, if set, names the person making changes to frozen
+		// clusters. Without it, those changes are blocked.
+		FreezeOverride string
 		*ResolveFilter
 	}
 
@@ -92,16 +103,20 @@ func NewResolver(d Deployer, r Registry, rf *ResolveFilter) *Resolver {
 	}
 }
 
-// Rectify takes a DiffChans and issues the commands to the infrastructure to reconcile the differences
+// Rectify takes a DiffChans and issues the commands to the infrastructure to
+// reconcile the differences. Each cluster's changes are shared between
+// Cluster.Concurrency workers.
 func (r *Resolver) rectify(dcs DiffChans) chan RectificationError {
-	d := r.Deployer
 	errs := make(chan RectificationError)
-	wg := &sync.WaitGroup{}
-	wg.Add(3)
-	go func() { d.RectifyCreates(dcs.Created, errs); wg.Done() }()
-	go func() { d.RectifyDeletes(dcs.Deleted, errs); wg.Done() }()
-	go func() { d.RectifyModifies(dcs.Modified, errs); wg.Done() }()
-	go func() { wg.Wait(); close(errs) }()
+	size := cap(dcs.Created)
+	if cap(dcs.Deleted) > size {
+		size = cap(dcs.Deleted)
+	}
+	if cap(dcs.Modified) > size {
+		size = cap(dcs.Modified)
+	}
+	pool := newRectifyPool(r.Deployer, errs, size)
+	go func() { pool.run(dcs); close(errs) }()
 
 	return errs
 }
@@ -111,24 +126,43 @@ func (r *Resolver) rectify(dcs DiffChans) chan RectificationError {
 // actual set, compute the diffs and then issue the commands to rectify those
 // differences.
 func (r *Resolver) Resolve(intended Deployments, clusters Clusters) error {
-	var ads Deployments
 	var diffs DiffChans
 	var errs chan RectificationError
-	return firsterr.Returned(
+	var tombErrs chan error
+	rec := NewResolveRecord(r.ResolveFilter)
+	err := firsterr.Returned(
+		func() (e error) { diffs, tombErrs, e = r.diff(intended, clusters); return },
+		func() (e error) { diffs = rec.watch(r.guardFreezes(diffs, rec)); return },
+		func() (e error) { errs = r.rectify(diffs); return },
+		func() (e error) { return r.recordRollbacks(foldErrors(errs)) },
+		func() (e error) { return <-tombErrs },
+	)
+	r.recordResolve(rec, err)
+	return err
+}
+
+// diff collects the actual deployments and computes how they differ from the
+// intended ones, after filtering both and guarding deletions.
+func (r *Resolver) diff(intended Deployments, clusters Clusters) (diffs DiffChans, tombErrs chan error, err error) {
+	var ads Deployments
+	err = firsterr.Returned(
 		func() (e error) { clusters = r.FilteredClusters(clusters); return },
 		func() (e error) { ads, e = r.Deployer.RunningDeployments(clusters); return },
 		func() (e error) { intended = intended.Filter(r.FilterDeployment); return },
 		func() (e error) { ads = ads.Filter(r.FilterDeployment); return },
+		func() (e error) { countDeployments(clusters, intended, ads); return },
+		func() (e error) { return r.holdRollbacks(intended, ads, clusters) },
 		func() (e error) { return GuardImages(r.Registry, intended) },
 		func() (e error) { diffs = ads.Diff(intended); return },
-		func() (e error) { errs = r.rectify(diffs); return },
-		func() (e error) { return foldErrors(errs) },
+		func() (e error) { diffs.Deleted, tombErrs = r.guardDeletes(diffs.Deleted, intended, clusters); return },
 	)
+	return
 }
 
 func foldErrors(errs chan RectificationError) error {
 	re := &ResolveErrors{Causes: []error{}}
 	for err := range errs {
+		countRectificationError(err)
 		re.Causes = append(re.Causes, err)
 		Log.Debug.Printf("resolve error = %+v\n", err)
 	}
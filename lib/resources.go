@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+
+	"github.com/pkg/errors"
 )
 
 type (
@@ -14,13 +16,34 @@ type (
 	Resources map[string]string
 
 	// A MissingResourceFlaw captures the absence of a required resource field,
-	// and tries to repair it from the state defaults
+	// and tries to repair it from the defaults of the cluster it's for.
 	MissingResourceFlaw struct {
 		Resources
+		ClusterName    string
 		Field, Default string
+		// specs, if set, holds the DeploySpec of ClusterName, which Repair
+		// gives Resources to if it has none.
+		specs DeploySpecs
 	}
 )
 
+// requiredResources are the resources every deployment needs, with the
+// values used for them when their cluster has no default.
+var requiredResources = []struct{ name, def string }{
+	{"cpus", "0.1"},
+	{"memory", "100"},
+	{"ports", "1"},
+}
+
+func isRequiredResource(name string) bool {
+	for _, rr := range requiredResources {
+		if rr.name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Clone returns a deep copy of this Resources.
 func (r Resources) Clone() Resources {
 	rs := make(Resources, len(r))
@@ -30,18 +53,28 @@ func (r Resources) Clone() Resources {
 	return rs
 }
 
-// AddContext implements Flaw.AddContext.
+// AddContext implements Flaw.AddContext. Given the name of the cluster the
+// resources are for, and then the state, it takes the default from that
+// cluster's limits.
 func (f *MissingResourceFlaw) AddContext(name string, i interface{}) {
-	/*
-		// I'd misremembered that the State.Defs held the GDM-wide defaults
-		// which isn't true. Leaving this here to sort of demostrate the idea
-		if name != "state" {
+	switch name {
+	case "cluster name":
+		if clusterName, is := i.(string); is {
+			f.ClusterName = clusterName
+		}
+	case "state":
+		state, is := i.(*State)
+		if !is {
 			return
 		}
-		if state, is := i.(*State); is {
-			f.State = state
+		cluster, has := state.Defs.Clusters[f.ClusterName]
+		if !has {
+			return
+		}
+		if def := cluster.Resources[f.Field].Default; def != "" {
+			f.Default = def
 		}
-	*/
+	}
 }
 
 func (f *MissingResourceFlaw) String() string {
@@ -50,6 +83,15 @@ func (f *MissingResourceFlaw) String() string {
 
 // Repair adds all missing fields set to default values.
 func (f *MissingResourceFlaw) Repair() error {
+	if f.Default == "" {
+		return errors.Errorf("resource %q in cluster %q missing, and has no default", f.Field, f.ClusterName)
+	}
+	if f.Resources == nil && f.specs != nil {
+		spec := f.specs[f.ClusterName]
+		spec.Resources = make(Resources)
+		f.specs[f.ClusterName] = spec
+		f.Resources = spec.Resources
+	}
 	f.Resources[f.Field] = f.Default
 	return nil
 }
@@ -58,14 +100,10 @@ func (f *MissingResourceFlaw) Repair() error {
 func (r Resources) Validate() []Flaw {
 	var flaws []Flaw
 
-	if f := r.validateField("cpus", "0.1"); f != nil {
-		flaws = append(flaws, f)
-	}
-	if f := r.validateField("memory", "100"); f != nil {
-		flaws = append(flaws, f)
-	}
-	if f := r.validateField("ports", "1"); f != nil {
-		flaws = append(flaws, f)
+	for _, rr := range requiredResources {
+		if f := r.validateField(rr.name, rr.def); f != nil {
+			flaws = append(flaws, f)
+		}
 	}
 
 	return flaws
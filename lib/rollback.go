@@ -0,0 +1,160 @@
+package sous
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samsalisbury/semv"
+)
+
+type (
+	// A Rollback records that a version of a deployment failed to roll out
+	// and was rolled back. While the GDM still asks for that version, the
+	// Resolver leaves the rolled back deployment alone rather than trying the
+	// bad version again.
+	Rollback struct {
+		// Deployment identifies the deployment that was rolled back.
+		Deployment DeployID
+		// Version is the version that failed to roll out.
+		Version semv.Version
+		// At is when the rollback happened.
+		At time.Time
+		// Reason is the error that caused the rollback.
+		Reason string
+	}
+
+	// Rollbacks is a collection of Rollback.
+	Rollbacks []*Rollback
+
+	// A RollbackKeeper stores the record of rollbacks between resolutions.
+	RollbackKeeper interface {
+		ReadRollbacks() (Rollbacks, error)
+		WriteRollbacks(Rollbacks) error
+	}
+
+	// StateRollbackKeeper keeps rollbacks in State.Rollbacks.
+	StateRollbackKeeper struct {
+		StateManager
+	}
+)
+
+// Get returns the rollback recorded for a deployment, if there is one.
+func (rs Rollbacks) Get(id DeployID) (*Rollback, bool) {
+	for _, r := range rs {
+		if r.Deployment == id {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// Clone returns a deep copy of this Rollbacks.
+func (rs Rollbacks) Clone() Rollbacks {
+	if rs == nil {
+		return nil
+	}
+	c := make(Rollbacks, 0, len(rs))
+	for _, r := range rs {
+		rc := *r
+		c = append(c, &rc)
+	}
+	return c
+}
+
+// ReadRollbacks implements RollbackKeeper
+func (k StateRollbackKeeper) ReadRollbacks() (Rollbacks, error) {
+	s, err := k.ReadState()
+	if err != nil {
+		return nil, err
+	}
+	return s.Rollbacks, nil
+}
+
+// WriteRollbacks implements RollbackKeeper
+func (k StateRollbackKeeper) WriteRollbacks(rs Rollbacks) error {
+	s, err := k.ReadState()
+	if err != nil {
+		return err
+	}
+	s.Rollbacks = rs
+	return k.WriteState(s)
+}
+
+// holdRollbacks replaces intended deployments whose version was rolled back
+// with the deployments actually running, so that the bad version isn't
+// deployed again. Rollback records are dropped once the intended version
+// moves on.
+func (r *Resolver) holdRollbacks(intended, ads Deployments, clusters Clusters) error {
+	if r.Rollbacks == nil {
+		return nil
+	}
+	old, err := r.Rollbacks.ReadRollbacks()
+	if err != nil || len(old) == 0 {
+		return errors.Wrap(err, "reading rollbacks")
+	}
+
+	var kept Rollbacks
+	for _, rb := range old {
+		d, ok := intended.Get(rb.Deployment)
+		if !ok {
+			if r.considers(rb.Deployment, clusters) {
+				continue
+			}
+			kept = append(kept, rb)
+			continue
+		}
+		if !d.SourceID.Version.Equals(rb.Version) {
+			continue
+		}
+		kept = append(kept, rb)
+		if running, ok := ads.Get(rb.Deployment); ok {
+			Log.Warn.Printf("NOT DEPLOYING %q version %s to %q: it was rolled back at %s: %s",
+				rb.Deployment.ManifestID, rb.Version, rb.Deployment.Cluster, rb.At.Format(time.RFC3339), rb.Reason)
+			intended.Set(rb.Deployment, running)
+		}
+	}
+
+	if len(kept) == len(old) {
+		return nil
+	}
+	return errors.Wrap(r.Rollbacks.WriteRollbacks(kept), "writing rollbacks")
+}
+
+// recordRollbacks adds a record for each RollbackError in a resolution's
+// errors, and passes those errors on.
+func (r *Resolver) recordRollbacks(err error) error {
+	re, ok := err.(*ResolveErrors)
+	if !ok || r.Rollbacks == nil {
+		return err
+	}
+	var rbs Rollbacks
+	for _, e := range re.Causes {
+		rbe, ok := e.(*RollbackError)
+		if !ok {
+			continue
+		}
+		rbs = append(rbs, &Rollback{
+			Deployment: rbe.Deployments.Post.ID(),
+			Version:    rbe.Deployments.Post.SourceID.Version,
+			At:         time.Now(),
+			Reason:     rbe.Err.Error(),
+		})
+	}
+	if len(rbs) == 0 {
+		return err
+	}
+
+	old, rerr := r.Rollbacks.ReadRollbacks()
+	if rerr == nil {
+		for _, rb := range old {
+			if _, replaced := rbs.Get(rb.Deployment); !replaced {
+				rbs = append(rbs, rb)
+			}
+		}
+		rerr = r.Rollbacks.WriteRollbacks(rbs)
+	}
+	if rerr != nil {
+		re.Causes = append(re.Causes, errors.Wrap(rerr, "recording rollbacks"))
+	}
+	return re
+}
@@ -0,0 +1,68 @@
+package sous
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+type memRollbacks struct {
+	rs Rollbacks
+}
+
+func (m *memRollbacks) ReadRollbacks() (Rollbacks, error) { return m.rs.Clone(), nil }
+
+func (m *memRollbacks) WriteRollbacks(rs Rollbacks) error {
+	m.rs = rs.Clone()
+	return nil
+}
+
+func rollbackDeployment(cluster *Cluster, sid string) *Deployment {
+	return &Deployment{
+		SourceID:     MustParseSourceID(sid),
+		ClusterName:  cluster.Name,
+		Cluster:      cluster,
+		DeployConfig: DeployConfig{NumInstances: 1},
+	}
+}
+
+func TestRecordAndHoldRollbacks(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cluster := &Cluster{Name: "a"}
+	clusters := Clusters{"a": cluster}
+	good := rollbackDeployment(cluster, "github.com/ot/one,1.0.0")
+	bad := rollbackDeployment(cluster, "github.com/ot/one,2.0.0")
+
+	keeper := &memRollbacks{}
+	r := &Resolver{Rollbacks: keeper, ResolveFilter: &ResolveFilter{}}
+
+	cause := errors.New("crashed")
+	err := r.recordRollbacks(&ResolveErrors{Causes: []error{
+		&RollbackError{Deployments: &DeploymentPair{Prior: good, Post: bad}, Err: cause},
+	}})
+	assert.Error(err)
+	if assert.Len(keeper.rs, 1) {
+		assert.Equal(bad.ID(), keeper.rs[0].Deployment)
+		assert.Equal("2.0.0", keeper.rs[0].Version.String())
+		assert.Equal("crashed", keeper.rs[0].Reason)
+	}
+
+	// While the GDM still asks for the bad version, the running one is held.
+	intended := NewDeployments(bad.Clone())
+	require.NoError(r.holdRollbacks(intended, NewDeployments(good), clusters))
+	held, _ := intended.Get(bad.ID())
+	assert.Equal("1.0.0", held.SourceID.Version.String())
+	assert.Len(keeper.rs, 1)
+
+	// Once the GDM moves on, the record is dropped.
+	fixed := rollbackDeployment(cluster, "github.com/ot/one,2.0.1")
+	intended = NewDeployments(fixed)
+	require.NoError(r.holdRollbacks(intended, NewDeployments(good), clusters))
+	held, _ = intended.Get(fixed.ID())
+	assert.Equal("2.0.1", held.SourceID.Version.String())
+	assert.Empty(keeper.rs)
+}
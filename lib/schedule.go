@@ -0,0 +1,115 @@
+package sous
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+// cronFields describe the five fields of a cron schedule. Names, where a
+// field accepts them, are numbered from the field's min.
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+// ValidateSchedule checks that schedule is a five field cron schedule, e.g.
+// "30 4 * * MON-FRI".
+func ValidateSchedule(schedule string) error {
+	fields := strings.Fields(schedule)
+	if len(fields) != len(cronFields) {
+		return errors.Errorf("schedule %q has %d fields, not %d", schedule, len(fields), len(cronFields))
+	}
+	for i, f := range fields {
+		if err := cronFields[i].validate(f); err != nil {
+			return errors.Wrapf(err, "schedule %q", schedule)
+		}
+	}
+	return nil
+}
+
+// ValidateTimeZone checks that tz names a known time zone, e.g.
+// "America/Los_Angeles".
+func ValidateTimeZone(tz string) error {
+	if tz == "" || tz == "Local" {
+		return errors.Errorf("time zone %q not valid", tz)
+	}
+	_, err := time.LoadLocation(tz)
+	return errors.Wrapf(err, "time zone %q", tz)
+}
+
+func (cf cronField) validate(field string) error {
+	for _, part := range strings.Split(field, ",") {
+		if err := cf.validatePart(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cf cronField) validatePart(part string) error {
+	rng := part
+	if i := strings.Index(part, "/"); i != -1 {
+		rng = part[:i]
+		step, err := strconv.Atoi(part[i+1:])
+		if err != nil || step < 1 {
+			return errors.Errorf("%s step %q not valid", cf.name, part[i+1:])
+		}
+	}
+	if rng == "*" {
+		return nil
+	}
+	bounds := strings.SplitN(rng, "-", 2)
+	low, err := cf.value(bounds[0])
+	if err != nil {
+		return err
+	}
+	if len(bounds) == 1 {
+		return nil
+	}
+	high, err := cf.value(bounds[1])
+	if err != nil {
+		return err
+	}
+	if high < low {
+		return errors.Errorf("%s range %q is backwards", cf.name, rng)
+	}
+	return nil
+}
+
+func (cf cronField) value(v string) (int, error) {
+	for i, n := range cf.names {
+		if strings.EqualFold(v, n) {
+			return cf.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < cf.min || n > cf.max {
+		return 0, errors.Errorf("%s %q not valid, should be %s", cf.name, v, cf.describe())
+	}
+	return n, nil
+}
+
+func (cf cronField) describe() string {
+	if len(cf.names) == 0 {
+		return fmt.Sprintf("%d-%d", cf.min, cf.max)
+	}
+	return fmt.Sprintf("%d-%d or %s-%s", cf.min, cf.max, cf.names[0], cf.names[len(cf.names)-1])
+}
+
+// isScheduled returns true for kinds of manifest which run on a schedule.
+func (mk ManifestKind) isScheduled() bool {
+	return mk == ManifestKindScheduled || mk == ScheduledJob
+}
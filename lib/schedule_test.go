@@ -0,0 +1,91 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, good := range []string{
+		"* * * * *",
+		"30 4 * * MON-FRI",
+		"*/15 0-6,18-23 1,15 jan-jun 0",
+		"0 12 31 DEC 7",
+	} {
+		assert.NoError(ValidateSchedule(good), good)
+	}
+	for _, bad := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * * SUNDAY",
+		"@daily",
+	} {
+		assert.Error(ValidateSchedule(bad), bad)
+	}
+}
+
+func TestValidateTimeZone(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(ValidateTimeZone("UTC"))
+	assert.NoError(ValidateTimeZone("America/Los_Angeles"))
+	assert.Error(ValidateTimeZone("Local"))
+	assert.Error(ValidateTimeZone("Mars/Olympus_Mons"))
+}
+
+func TestDeployConfigScheduleFlaws(t *testing.T) {
+	assert := assert.New(t)
+
+	dc := DeployConfig{Resources: Resources{"cpus": "1", "memory": "100", "ports": "1"}}
+	assert.Empty(dc.Validate())
+
+	dc.Schedule = "0 4 * * *"
+	dc.ScheduleTimeZone = "Europe/London"
+	assert.Empty(dc.Validate())
+
+	dc.ScheduleTimeZone = "Nowhere"
+	assert.Len(dc.Validate(), 1)
+
+	dc.Schedule = ""
+	dc.ScheduleTimeZone = "UTC"
+	assert.Len(dc.Validate(), 1)
+}
+
+func TestManifestNeedsScheduleForScheduledKinds(t *testing.T) {
+	assert := assert.New(t)
+
+	spec := DeploySpec{DeployConfig: DeployConfig{Resources: Resources{"cpus": "1", "memory": "100", "ports": "1"}}}
+	m := &Manifest{Kind: ManifestKindScheduled, Deployments: DeploySpecs{"a": spec}}
+	assert.Len(m.Validate(), 1)
+
+	spec.Schedule = "0 4 * * *"
+	m.Deployments["a"] = spec
+	assert.Empty(m.Validate())
+
+	m.Kind = ManifestKindService
+	assert.Len(m.Validate(), 1)
+}
+
+func TestDeployConfigDiffSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	a := DeployConfig{Schedule: "0 4 * * *"}
+	b := DeployConfig{Schedule: "0 4 * * *", ScheduleTimeZone: "UTC"}
+	_, diffs := a.Diff(b)
+	assert.Equal([]string{`schedule time zone; this: ""; other: "UTC"`}, diffs)
+
+	b.Schedule = "0 5 * * *"
+	_, diffs = a.Diff(b)
+	assert.Len(diffs, 2)
+}
@@ -0,0 +1,74 @@
+package sous
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SecretRefPrefix marks an Env value as a reference to a secret, rather than
+// the value itself, e.g. "secret://db/prod/password". Everything up to the
+// last "/" is the path of the secret, and the rest is its key.
+//
+// References are kept as they are in the state, in diffs and in logs. They
+// are only resolved when the deploy is sent to the scheduler.
+const SecretRefPrefix = "secret://"
+
+// A SecretProvider looks up the values of secrets.
+type SecretProvider interface {
+	// Secret returns the value stored under key in the secret at path.
+	Secret(path, key string) (string, error)
+}
+
+// IsSecretRef returns true if v is a reference to a secret.
+func IsSecretRef(v string) bool {
+	return strings.HasPrefix(v, SecretRefPrefix)
+}
+
+// ParseSecretRef splits a secret reference into its path and key.
+func ParseSecretRef(ref string) (path, key string, err error) {
+	if !IsSecretRef(ref) {
+		return "", "", errors.Errorf("%q is not a secret reference", ref)
+	}
+	rest := strings.TrimPrefix(ref, SecretRefPrefix)
+	i := strings.LastIndex(rest, "/")
+	if i < 1 || i == len(rest)-1 {
+		return "", "", errors.Errorf("secret reference %q should be %spath/key", ref, SecretRefPrefix)
+	}
+	return rest[:i], rest[i+1:], nil
+}
+
+// SecretRefs returns the variables in e whose values are secret references.
+func (e Env) SecretRefs() Env {
+	refs := Env{}
+	for name, v := range e {
+		if IsSecretRef(v) {
+			refs[name] = v
+		}
+	}
+	return refs
+}
+
+// ResolveSecrets returns a copy of e with each secret reference replaced by
+// the value sp has for it. Errors name the variable, but never include a
+// secret's value.
+func (e Env) ResolveSecrets(sp SecretProvider) (Env, error) {
+	resolved := make(Env, len(e))
+	for name, v := range e {
+		resolved[name] = v
+		if !IsSecretRef(v) {
+			continue
+		}
+		if sp == nil {
+			return nil, errors.Errorf("env var %s refers to secret %q, but no secret provider is configured", name, v)
+		}
+		path, key, err := ParseSecretRef(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "env var %s", name)
+		}
+		if resolved[name], err = sp.Secret(path, key); err != nil {
+			return nil, errors.Wrapf(err, "env var %s: resolving %q", name, v)
+		}
+	}
+	return resolved, nil
+}
@@ -0,0 +1,51 @@
+package sous
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+type mapSecrets map[string]string
+
+func (ms mapSecrets) Secret(path, key string) (string, error) {
+	v, ok := ms[path+"/"+key]
+	if !ok {
+		return "", fmt.Errorf("no secret %s/%s", path, key)
+	}
+	return v, nil
+}
+
+func TestParseSecretRef(t *testing.T) {
+	assert := assert.New(t)
+
+	path, key, err := ParseSecretRef("secret://db/prod/password")
+	assert.NoError(err)
+	assert.Equal("db/prod", path)
+	assert.Equal("password", key)
+
+	for _, bad := range []string{"db/prod/password", "secret://password", "secret://db/", "secret:///password"} {
+		_, _, err := ParseSecretRef(bad)
+		assert.Error(err, bad)
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	env := Env{"USER": "app", "PASSWORD": "secret://db/prod/password"}
+	assert.Equal(Env{"PASSWORD": "secret://db/prod/password"}, env.SecretRefs())
+
+	resolved, err := env.ResolveSecrets(mapSecrets{"db/prod/password": "hunter2"})
+	require.NoError(err)
+	assert.Equal(Env{"USER": "app", "PASSWORD": "hunter2"}, resolved)
+	assert.Equal("secret://db/prod/password", env["PASSWORD"])
+
+	_, err = env.ResolveSecrets(nil)
+	assert.Error(err)
+	_, err = env.ResolveSecrets(mapSecrets{})
+	assert.Error(err)
+}
@@ -11,6 +11,12 @@ type (
 		// Manifests contains a mapping of source code repositories to global
 		// deployment configurations for artifacts built using that source code.
 		Manifests Manifests `hy:"manifests/"`
+		// Tombstones records deployments which are awaiting deletion under
+		// their cluster's DeletePolicy.
+		Tombstones Tombstones `hy:"tombstones"`
+		// Rollbacks records deployments whose latest version failed to roll
+		// out, and was rolled back.
+		Rollbacks Rollbacks `hy:"rollbacks"`
 	}
 
 	// Defs holds definitions for organisation-level objects.
@@ -28,7 +34,9 @@ type (
 
 	// EnvDefs is a collection of EnvDef
 	EnvDefs []EnvDef
-	// EnvDef is an environment variable definition.
+	// EnvDef is an environment variable definition. Values manifests give
+	// the variable are checked against its Type, and variables with the
+	// "cluster" Scope may only be set by clusters.
 	EnvDef struct {
 		Name, Desc, Scope string
 		Type              VarType
@@ -41,7 +49,8 @@ type (
 		// Name is the name of the resource, e.g. "Memory", "CPU", "NumPorts"
 		Name string
 		// Type is the type of value used to represent quantities or instances
-		// of this resource, e.g. MemorySize, Float, or Int (not yet implemented).
+		// of this resource: "int", "float", "memory_size" or "string". Resource
+		// values in manifests are checked against it.
 		Type VarType
 	}
 
@@ -52,8 +61,9 @@ type (
 	Cluster struct {
 		// Name is the unique name of this cluster.
 		Name string
-		// Kind is the kind of cluster. Currently the only legal value is
-		// "singularity"
+		// Kind is the kind of cluster, which selects the Deployer used to
+		// manage it: "singularity" (the default, if Kind is empty) or
+		// "kubernetes".
 		Kind string
 		// BaseURL is the main entrypoint URL for interacting with this cluster.
 		BaseURL string
@@ -62,6 +72,26 @@ type (
 		// AllowedAdvisories lists the artifact advisories which are permissible in
 		// this cluster
 		AllowedAdvisories []string
+		// DeletePolicy controls whether, and when, deployments running in this
+		// cluster without a manifest are deleted.
+		DeletePolicy DeletePolicy
+		// AutoRollback, if true, makes Sous redeploy the previous version of
+		// any deployment in this cluster whose new version fails to roll out.
+		AutoRollback bool
+		// Freeze stops Sous from creating or changing deployments in this
+		// cluster.
+		Freeze Freeze
+		// Concurrency is the number of deployments in this cluster which may
+		// be rectified at once. It defaults to 1.
+		Concurrency int
+		// RateLimit is the greatest number of requests per second Sous makes
+		// to create, change or delete deployments in this cluster. Zero means
+		// no limit. Only Singularity clusters may set it, and reads, such as
+		// listing running deployments and polling deploys, aren't limited.
+		RateLimit float64
+		// Resources limits the resources deployments in this cluster may
+		// use, and supplies defaults for them.
+		Resources ResourceLimits `yaml:",omitempty"`
 	}
 
 	// EnvDefaults is a list of named environment variables along with their values.
@@ -85,6 +115,8 @@ func NewState() *State {
 func (s State) Clone() *State {
 	s.Manifests = s.Manifests.Clone()
 	s.Defs = s.Defs.Clone()
+	s.Tombstones = s.Tombstones.Clone()
+	s.Rollbacks = s.Rollbacks.Clone()
 	return &s
 }
 
@@ -108,25 +140,49 @@ func (cs Clusters) Clone() Clusters {
 // Clone returns a deep copy of this Cluster.
 func (c Cluster) Clone() *Cluster {
 	allowedAdvisories := make([]string, len(c.AllowedAdvisories))
-	copy(c.AllowedAdvisories, allowedAdvisories)
+	copy(allowedAdvisories, c.AllowedAdvisories)
 	c.AllowedAdvisories = allowedAdvisories
+	if c.Resources != nil {
+		resources := make(ResourceLimits, len(c.Resources))
+		for name, limit := range c.Resources {
+			resources[name] = limit
+		}
+		c.Resources = resources
+	}
 	return &c
 }
 
 // Clone returns a deep copy of this EnvDefs.
 func (evs EnvDefs) Clone() EnvDefs {
 	e := make(EnvDefs, len(evs))
-	copy(evs, e)
+	copy(e, evs)
 	return e
 }
 
 // Clone returns a deep copy of this ResDefs.
 func (rdf ResDefs) Clone() ResDefs {
 	r := make(ResDefs, len(rdf))
-	copy(rdf, r)
+	copy(r, rdf)
 	return r
 }
 
+// CheckManifest checks the resources and environment m sets for each cluster
+// against these definitions. Resources are only checked if some are defined.
+func (d Defs) CheckManifest(m *Manifest) []Flaw {
+	var flaws []Flaw
+	for _, clusterName := range m.ClusterNames() {
+		spec := m.ClusterSpec(clusterName)
+		if len(d.Resources) != 0 {
+			flaws = append(flaws, m.ownFlaws(clusterName, d.Resources.check(spec.Resources, clusterName, d.Clusters[clusterName]))...)
+		}
+		flaws = append(flaws, d.EnvVars.check(spec.Env, clusterName)...)
+	}
+	for _, f := range flaws {
+		f.AddContext("manifest", m)
+	}
+	return flaws
+}
+
 // ClusterMap returns the nicknames for all the clusters referred to in this state
 // paired with the URL for the named cluster
 func (s *State) ClusterMap() map[string]string {
@@ -153,6 +209,24 @@ func (s *State) Validate() []Flaw {
 
 	for _, manifest := range s.Manifests.Snapshot() {
 		flaws = append(flaws, manifest.Validate()...)
+		flaws = append(flaws, s.Defs.CheckManifest(manifest)...)
+	}
+	flaws = append(flaws, s.Defs.Resources.Validate(s.Defs.Clusters)...)
+	flaws = append(flaws, s.Defs.EnvVars.Validate(s.Defs.Clusters)...)
+
+	for _, cluster := range s.Defs.Clusters {
+		for _, f := range cluster.DeletePolicy.Validate() {
+			f.AddContext("cluster", cluster)
+			flaws = append(flaws, f)
+		}
+		for _, f := range cluster.Freeze.Validate() {
+			f.AddContext("cluster", cluster)
+			flaws = append(flaws, f)
+		}
+		for _, f := range cluster.validateRateLimit() {
+			f.AddContext("cluster", cluster)
+			flaws = append(flaws, f)
+		}
 	}
 
 	for _, f := range flaws {
@@ -0,0 +1,81 @@
+package sous
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ManifestsDeployingTo returns the IDs of the manifests which deploy to the
+// named cluster, in order.
+func (s *State) ManifestsDeployingTo(cluster string) []ManifestID {
+	var ids []ManifestID
+	for id, m := range s.Manifests.Snapshot() {
+		if _, ok := m.Deployments[cluster]; ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(manifestIDs(ids))
+	return ids
+}
+
+// SetDefs replaces the state's Defs. It returns an error, and leaves the
+// state alone, if defs doesn't define a cluster that a manifest deploys to.
+// Other problems are found by Validate.
+func (s *State) SetDefs(defs Defs) error {
+	var missing []string
+	for _, m := range s.Manifests.Snapshot() {
+		for _, name := range m.ClusterNames() {
+			if _, ok := defs.Clusters[name]; !ok {
+				missing = append(missing, name+" (used by "+m.ID().String()+")")
+			}
+		}
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return errors.Errorf("clusters are deployed to, but not defined: %s", strings.Join(missing, ", "))
+	}
+	s.Defs = defs
+	return nil
+}
+
+// SetCluster adds the named cluster to the state's Defs, or replaces it.
+// If c has no Name, it is given name.
+func (s *State) SetCluster(name string, c *Cluster) error {
+	if name == "" || name == GlobalDeploySpec {
+		return errors.Errorf("%q is not a valid cluster name", name)
+	}
+	if c.Name == "" {
+		c.Name = name
+	}
+	if c.Name != name {
+		return errors.Errorf("cluster %q is named %q", name, c.Name)
+	}
+	if s.Defs.Clusters == nil {
+		s.Defs.Clusters = Clusters{}
+	}
+	s.Defs.Clusters[name] = c
+	return nil
+}
+
+// DeleteCluster removes the named cluster from the state's Defs. It returns
+// an error, and leaves the state alone, if any manifest still deploys to
+// the cluster.
+func (s *State) DeleteCluster(name string) error {
+	if ids := s.ManifestsDeployingTo(name); len(ids) != 0 {
+		names := make([]string, len(ids))
+		for i, id := range ids {
+			names[i] = id.String()
+		}
+		return errors.Errorf("cluster %q is still deployed to by %s", name, strings.Join(names, ", "))
+	}
+	delete(s.Defs.Clusters, name)
+	return nil
+}
+
+type manifestIDs []ManifestID
+
+func (ids manifestIDs) Len() int           { return len(ids) }
+func (ids manifestIDs) Swap(i, j int)      { ids[i], ids[j] = ids[j], ids[i] }
+func (ids manifestIDs) Less(i, j int) bool { return ids[i].String() < ids[j].String() }
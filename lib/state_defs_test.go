@@ -0,0 +1,74 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+func TestStateSetDefs(t *testing.T) {
+	assert := assert.New(t)
+
+	s := globalTestState()
+	defs := s.Defs.Clone()
+	delete(defs.Clusters, "cluster-2")
+
+	err := s.SetDefs(defs)
+	assert.Error(err)
+	assert.Contains(err.Error(), "cluster-2")
+	assert.Len(s.Defs.Clusters, 2)
+
+	defs = s.Defs.Clone()
+	defs.DockerRepo = "docker.example.com"
+	assert.NoError(s.SetDefs(defs))
+	assert.Equal("docker.example.com", s.Defs.DockerRepo)
+}
+
+func TestStateSetCluster(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := globalTestState()
+	require.NoError(s.SetCluster("cluster-3", &Cluster{BaseURL: "http://three"}))
+	require.Contains(s.Defs.Clusters, "cluster-3")
+	assert.Equal("cluster-3", s.Defs.Clusters["cluster-3"].Name)
+
+	assert.Error(s.SetCluster("cluster-4", &Cluster{Name: "cluster-5"}))
+	assert.Error(s.SetCluster(GlobalDeploySpec, &Cluster{}))
+	assert.NotContains(s.Defs.Clusters, "cluster-4")
+}
+
+func TestStateDeleteCluster(t *testing.T) {
+	assert := assert.New(t)
+
+	s := globalTestState()
+	s.Defs.Clusters["unused"] = &Cluster{Name: "unused"}
+
+	err := s.DeleteCluster("cluster-1")
+	assert.Error(err)
+	assert.Contains(err.Error(), "github.com/opentable/global")
+	assert.Contains(s.Defs.Clusters, "cluster-1")
+
+	assert.NoError(s.DeleteCluster("unused"))
+	assert.NotContains(s.Defs.Clusters, "unused")
+}
+
+func TestDefsClone(t *testing.T) {
+	assert := assert.New(t)
+
+	defs := Defs{
+		EnvVars:   EnvDefs{{Name: "REGION"}},
+		Resources: ResDefs{{Name: "cpus"}},
+		Clusters: Clusters{
+			"one": {Name: "one", AllowedAdvisories: []string{"ephemeral_tag"}},
+		},
+	}
+	clone := defs.Clone()
+	assert.Equal(defs, clone)
+
+	clone.EnvVars[0].Name = "CHANGED"
+	clone.Clusters["one"].AllowedAdvisories[0] = "changed"
+	assert.Equal("REGION", defs.EnvVars[0].Name)
+	assert.Equal("ephemeral_tag", defs.Clusters["one"].AllowedAdvisories[0])
+}
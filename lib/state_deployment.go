@@ -0,0 +1,93 @@
+package sous
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Deployment returns the deployment with id that the state intends, built
+// the same way as by Deployments. ok is false if there isn't one.
+func (s *State) Deployment(id DeployID) (d *Deployment, ok bool, err error) {
+	if id.Cluster == GlobalDeploySpec {
+		return nil, false, nil
+	}
+	m, ok := s.Manifests.Get(id.ManifestID)
+	if !ok {
+		return nil, false, nil
+	}
+	if _, ok := m.Deployments[id.Cluster]; !ok {
+		return nil, false, nil
+	}
+	ds, err := s.DeploymentsFromManifest(m)
+	if err != nil {
+		return nil, false, err
+	}
+	d, ok = ds.Get(id)
+	if !ok {
+		return nil, false, nil
+	}
+	return d, true, s.bindCluster(d)
+}
+
+// SetDeployment changes the DeploySpec for d's cluster in d's manifest, so
+// that the state intends d. Values d gets from the manifest's Global
+// DeploySpec, or from its cluster's default env vars, are left to be
+// inherited. The manifest must already exist.
+//
+// It returns an error, and leaves the state alone, if the manifest can't
+// express d: for example, if d has different owners, which are set for the
+// whole manifest, or leaves out an env var that the Global DeploySpec sets.
+func (s *State) SetDeployment(d *Deployment) error {
+	id := d.ID()
+	if id.Cluster == GlobalDeploySpec {
+		return errors.Errorf("%q is not a cluster", GlobalDeploySpec)
+	}
+	m, ok := s.Manifests.Get(id.ManifestID)
+	if !ok {
+		return errors.Errorf("no manifest %q", id.ManifestID)
+	}
+	cluster, ok := s.Defs.Clusters[id.Cluster]
+	if !ok || cluster == nil {
+		return errors.Errorf("cluster %q is not described in defs.yaml", id.Cluster)
+	}
+	if d.AutoRollback != m.AutoRollback {
+		return errors.Errorf("auto rollback is set for the whole of manifest %q", id.ManifestID)
+	}
+
+	spec := DeploySpec{DeployConfig: d.DeployConfig.Clone(), Version: d.SourceID.Version}
+	for name, v := range cluster.Env {
+		if sv, ok := spec.Env[name]; ok && sv == string(v) {
+			delete(spec.Env, name)
+		}
+	}
+	changed := m.Clone()
+	if flaws := changed.SetClusterSpec(id.Cluster, spec); len(flaws) > 0 {
+		return errors.Errorf("manifest %q can't express this deployment: %v", id.ManifestID, flaws)
+	}
+
+	s.Manifests.Set(id.ManifestID, changed)
+	set, _, err := s.Deployment(id)
+	if err == nil {
+		if different, diffs := d.Diff(set); different {
+			err = errors.Errorf("manifest %q can't express this deployment: %s", id.ManifestID, strings.Join(diffs, "; "))
+		}
+	}
+	if err != nil {
+		s.Manifests.Set(id.ManifestID, m)
+	}
+	return err
+}
+
+// DeleteDeployment removes the DeploySpec for the cluster of id from its
+// manifest. It returns false if there was no such DeploySpec.
+func (s *State) DeleteDeployment(id DeployID) bool {
+	if _, ok, _ := s.Deployment(id); !ok {
+		return false
+	}
+	m, _ := s.Manifests.Get(id.ManifestID)
+	changed := m.Clone()
+	delete(changed.Deployments, id.Cluster)
+	s.Manifests.Set(id.ManifestID, changed)
+	return true
+}
@@ -0,0 +1,101 @@
+package sous
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/samsalisbury/semv"
+)
+
+func TestStateDeployment(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := globalTestState()
+	s.Defs.Clusters["cluster-1"].Env = EnvDefaults{"REGION": "east"}
+	mid := ManifestID{Source: SourceLocation{Repo: "github.com/opentable/global"}}
+
+	d, ok, err := s.Deployment(DeployID{ManifestID: mid, Cluster: "cluster-1"})
+	require.NoError(err)
+	require.True(ok)
+	assert.Equal("1.0.0", d.SourceID.Version.String())
+	assert.Equal(2, d.NumInstances)
+	assert.Equal(Env{"SHARED": "yes", "WHERE": "one", "REGION": "east"}, d.Env)
+
+	for _, missing := range []DeployID{
+		{ManifestID: mid, Cluster: GlobalDeploySpec},
+		{ManifestID: mid, Cluster: "cluster-3"},
+		{ManifestID: ManifestID{Source: SourceLocation{Repo: "nope"}}, Cluster: "cluster-1"},
+	} {
+		_, ok, err := s.Deployment(missing)
+		assert.NoError(err)
+		assert.False(ok, "%v", missing)
+	}
+}
+
+func TestStateSetDeployment(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := globalTestState()
+	s.Defs.Clusters["cluster-1"].Env = EnvDefaults{"REGION": "east"}
+	mid := ManifestID{Source: SourceLocation{Repo: "github.com/opentable/global"}}
+	id := DeployID{ManifestID: mid, Cluster: "cluster-1"}
+
+	d, _, err := s.Deployment(id)
+	require.NoError(err)
+	d.SourceID.Version = semv.MustParse("2.0.0")
+	d.Env["WHERE"] = "uno"
+	require.NoError(s.SetDeployment(d))
+
+	m, _ := s.Manifests.Get(mid)
+	spec := m.Deployments["cluster-1"]
+	assert.Equal("2.0.0", spec.Version.String())
+	// Values from the Global DeploySpec and the cluster are still inherited.
+	assert.Equal(Env{"WHERE": "uno"}, spec.Env)
+	assert.Equal(Resources{"memory": "256"}, spec.Resources)
+	assert.Equal("1.0.0", m.Deployments[GlobalDeploySpec].Version.String())
+
+	set, _, err := s.Deployment(id)
+	require.NoError(err)
+	different, diffs := d.Diff(set)
+	assert.False(different, "%v", diffs)
+}
+
+func TestStateSetDeploymentInexpressible(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	s := globalTestState()
+	mid := ManifestID{Source: SourceLocation{Repo: "github.com/opentable/global"}}
+	id := DeployID{ManifestID: mid, Cluster: "cluster-1"}
+	before, _ := s.Manifests.Get(mid)
+
+	d, _, err := s.Deployment(id)
+	require.NoError(err)
+	delete(d.Env, "SHARED")
+	assert.Error(s.SetDeployment(d))
+
+	d, _, err = s.Deployment(id)
+	require.NoError(err)
+	d.Owners = NewOwnerSet("somebody else")
+	assert.Error(s.SetDeployment(d))
+
+	after, _ := s.Manifests.Get(mid)
+	assert.Equal(before, after)
+}
+
+func TestStateDeleteDeployment(t *testing.T) {
+	assert := assert.New(t)
+
+	s := globalTestState()
+	mid := ManifestID{Source: SourceLocation{Repo: "github.com/opentable/global"}}
+
+	assert.True(s.DeleteDeployment(DeployID{ManifestID: mid, Cluster: "cluster-1"}))
+	assert.False(s.DeleteDeployment(DeployID{ManifestID: mid, Cluster: "cluster-1"}))
+	assert.False(s.DeleteDeployment(DeployID{ManifestID: mid, Cluster: GlobalDeploySpec}))
+
+	m, _ := s.Manifests.Get(mid)
+	assert.Equal([]string{"cluster-2"}, m.ClusterNames())
+}
@@ -0,0 +1,56 @@
+package sous
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The VarTypes that resource and environment values are checked against. An
+// empty VarType accepts any value.
+const (
+	VarTypeString     = VarType("string")
+	VarTypeInt        = VarType("int")
+	VarTypeFloat      = VarType("float")
+	VarTypeMemorySize = VarType("memory_size")
+)
+
+// isNumeric returns true for VarTypes whose values can be compared with a
+// ResourceLimit's Min and Max.
+func (vt VarType) isNumeric() bool {
+	switch VarType(strings.ToLower(string(vt))) {
+	case VarTypeInt, VarTypeFloat, VarTypeMemorySize:
+		return true
+	}
+	return false
+}
+
+// Validate checks that vt is one of the known VarTypes.
+func (vt VarType) Validate() error {
+	switch VarType(strings.ToLower(string(vt))) {
+	case "", VarTypeString, VarTypeInt, VarTypeFloat, VarTypeMemorySize:
+		return nil
+	}
+	return errors.Errorf("unknown type %q", vt)
+}
+
+// parse checks that v is a value of type vt, and returns it as a number for
+// numeric types.
+func (vt VarType) parse(v string) (float64, error) {
+	switch VarType(strings.ToLower(string(vt))) {
+	case VarTypeInt:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("%q is not an int", v)
+		}
+		return float64(n), nil
+	case VarTypeFloat, VarTypeMemorySize:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, errors.Errorf("%q is not a %s", v, vt)
+		}
+		return n, nil
+	}
+	return 0, nil
+}
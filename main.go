@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/opentable/sous/cli"
+	"github.com/opentable/sous/graph"
 )
 
 // Sous is the Sous CLI root command.
@@ -30,6 +31,9 @@ func main() {
 
 	result := c.Invoke(os.Args)
 	exitCode := result.ExitCode()
+	if err := graph.CloseResolveHistories(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
 
 	os.Exit(exitCode)
 }
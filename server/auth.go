@@ -0,0 +1,211 @@
+package server
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
+)
+
+type (
+	// An Identity is whoever made a request, as established by an
+	// Authenticator.
+	Identity struct {
+		// Name is compared with the owners of manifests, and with admins.
+		Name string
+	}
+
+	// An Authenticator establishes who made a request.
+	Authenticator interface {
+		// Authenticate returns the identity of whoever made r, or nil if r
+		// carries no credentials that the Authenticator looks for. It returns
+		// an error if r carries credentials which aren't valid.
+		Authenticate(r *http.Request) (*Identity, error)
+	}
+
+	// Authenticators tries each of its Authenticators in turn, and returns
+	// the first identity established.
+	Authenticators []Authenticator
+
+	// A TokenAuthenticator identifies callers by the bearer token in the
+	// Authorization header of their requests.
+	TokenAuthenticator struct {
+		// tokens maps each token to the name of whoever it identifies.
+		tokens map[string]string
+	}
+
+	// A TLSAuthenticator identifies callers by the common name of the client
+	// certificate they presented, if it was verified.
+	TLSAuthenticator struct{}
+
+	// Auth configures how a server authenticates callers, and who may
+	// change anything.
+	Auth struct {
+		Authenticator
+		// Admins are the names of callers who may change any manifest,
+		// whoever owns it.
+		Admins []string
+	}
+
+	// A Caller is whoever made the request being handled.
+	Caller struct {
+		// Identity is nil if the caller didn't authenticate.
+		*Identity
+		// Auth is nil if the server doesn't authenticate callers, in which
+		// case anybody may change anything.
+		*Auth
+	}
+
+	// An Authorizer is an Exchanger which checks that the caller may make
+	// the exchange. If not, it returns an error and status, and the exchange
+	// isn't made.
+	Authorizer interface {
+		Authorize() (*ClientError, int)
+	}
+)
+
+// Authenticate implements Authenticator on Authenticators.
+func (as Authenticators) Authenticate(r *http.Request) (*Identity, error) {
+	for _, a := range as {
+		id, err := a.Authenticate(r)
+		if id != nil || err != nil {
+			return id, err
+		}
+	}
+	return nil, nil
+}
+
+// NewTokenAuthenticator reads tokens from path, in the format described for
+// config.Config.AuthTokensFile.
+func NewTokenAuthenticator(path string) (*TokenAuthenticator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading auth tokens")
+	}
+	defer f.Close()
+
+	ta := &TokenAuthenticator{tokens: map[string]string{}}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, errors.Errorf("%s:%d: want a token and a name", path, n)
+		}
+		ta.tokens[fields[0]] = fields[1]
+	}
+	return ta, errors.Wrap(scanner.Err(), "reading auth tokens")
+}
+
+// Authenticate implements Authenticator on TokenAuthenticator.
+func (ta *TokenAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, nil
+	}
+	given := []byte(strings.TrimSpace(strings.TrimPrefix(header, "Bearer ")))
+	for token, name := range ta.tokens {
+		if subtle.ConstantTimeCompare(given, []byte(token)) == 1 {
+			return &Identity{Name: name}, nil
+		}
+	}
+	return nil, errors.New("unknown bearer token")
+}
+
+// Authenticate implements Authenticator on TLSAuthenticator.
+func (TLSAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, nil
+	}
+	name := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	if name == "" {
+		return nil, errors.New("client certificate has no common name")
+	}
+	return &Identity{Name: name}, nil
+}
+
+// IsAdmin returns true if the caller is one of the admins.
+func (c *Caller) IsAdmin() bool {
+	if c == nil || c.Identity == nil || c.Auth == nil {
+		return false
+	}
+	for _, admin := range c.Admins {
+		if admin == c.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// MayChange checks that the caller may change what, which belongs to
+// owners. Admins may change anything. If owners is empty, for example
+// because what doesn't exist yet, any caller who has authenticated may
+// change it.
+func (c *Caller) MayChange(what string, owners []string) (*ClientError, int) {
+	if c == nil || c.Auth == nil || c.IsAdmin() {
+		return nil, http.StatusOK
+	}
+	if c.Identity == nil {
+		return &ClientError{Message: fmt.Sprintf("Authenticate to change %s", what)}, http.StatusUnauthorized
+	}
+	if len(owners) == 0 {
+		return nil, http.StatusOK
+	}
+	for _, owner := range owners {
+		if owner == c.Name {
+			return nil, http.StatusOK
+		}
+	}
+	return &ClientError{
+		Message: fmt.Sprintf("%s may not change %s, which belongs to %s", c.Name, what, strings.Join(owners, ", ")),
+	}, http.StatusForbidden
+}
+
+// authorizeManifest checks that caller may change the manifest identified by
+// qv. Problems with qv itself are left to the exchange to report.
+func authorizeManifest(caller *Caller, state *sous.State, qv *QueryValues) (*ClientError, int) {
+	mid, err := manifestIDFromValues(qv)
+	if err != nil {
+		return nil, http.StatusOK
+	}
+	var owners []string
+	if m, there := state.Manifests.Get(mid); there {
+		owners = m.Owners
+	}
+	return caller.MayChange(fmt.Sprintf("manifest %q", mid), owners)
+}
+
+// authorizeDeployments checks that caller may change what it records about
+// each of ids, as an owner of the deployment's manifest.
+func authorizeDeployments(caller *Caller, state *sous.State, what string, ids []sous.DeployID) (*ClientError, int) {
+	for _, id := range ids {
+		var owners []string
+		if m, there := state.Manifests.Get(id.ManifestID); there {
+			owners = m.Owners
+		}
+		if ce, status := caller.MayChange(fmt.Sprintf("the %s of %q", what, id), owners); ce != nil {
+			return ce, status
+		}
+	}
+	return nil, http.StatusOK
+}
+
+// MayAdminister checks that the caller may change what, which belongs to
+// nobody in particular. Only admins may.
+func (c *Caller) MayAdminister(what string) (*ClientError, int) {
+	if c == nil || c.Auth == nil || c.IsAdmin() {
+		return nil, http.StatusOK
+	}
+	if c.Identity == nil {
+		return &ClientError{Message: fmt.Sprintf("Authenticate to change %s", what)}, http.StatusUnauthorized
+	}
+	return &ClientError{Message: fmt.Sprintf("%s may not change %s: only admins may", c.Name, what)}, http.StatusForbidden
+}
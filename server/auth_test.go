@@ -0,0 +1,125 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/samsalisbury/psyringe"
+)
+
+func writeTokens(t *testing.T, contents string) string {
+	f, err := ioutil.TempFile("", "sous-tokens")
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(contents)
+	require.NoError(t, err)
+	return f.Name()
+}
+
+func TestTokenAuthenticator(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	path := writeTokens(t, "# deployers\n\ns3cret sam\nhunter2 judson\n")
+	defer os.Remove(path)
+	ta, err := NewTokenAuthenticator(path)
+	require.NoError(err)
+
+	rq, err := http.NewRequest("GET", "/", nil)
+	require.NoError(err)
+	id, err := ta.Authenticate(rq)
+	assert.NoError(err)
+	assert.Nil(id)
+
+	rq.Header.Set("Authorization", "Bearer hunter2")
+	id, err = ta.Authenticate(rq)
+	assert.NoError(err)
+	require.NotNil(id)
+	assert.Equal("judson", id.Name)
+
+	rq.Header.Set("Authorization", "Bearer hunter3")
+	_, err = ta.Authenticate(rq)
+	assert.Error(err)
+}
+
+func TestTokenAuthenticatorBadFile(t *testing.T) {
+	path := writeTokens(t, "s3cret\n")
+	defer os.Remove(path)
+	_, err := NewTokenAuthenticator(path)
+	assert.Error(t, err)
+}
+
+func TestCallerMayChange(t *testing.T) {
+	assert := assert.New(t)
+
+	auth := &Auth{Admins: []string{"root"}}
+	owners := []string{"sam", "judson"}
+	status := func(c *Caller, owners []string) int {
+		_, s := c.MayChange("manifest", owners)
+		return s
+	}
+
+	assert.Equal(200, status(&Caller{}, owners))
+	assert.Equal(401, status(&Caller{Auth: auth}, owners))
+	assert.Equal(200, status(&Caller{&Identity{"sam"}, auth}, owners))
+	assert.Equal(403, status(&Caller{&Identity{"mallory"}, auth}, owners))
+	assert.Equal(200, status(&Caller{&Identity{"mallory"}, auth}, nil))
+	assert.Equal(200, status(&Caller{&Identity{"root"}, auth}, owners))
+}
+
+func TestAuthRouterChecksManifestOwners(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	path := writeTokens(t, "s3cret sam\nhunter2 mallory\n")
+	defer os.Remove(path)
+	ta, err := NewTokenAuthenticator(path)
+	require.NoError(err)
+
+	state := sous.NewState()
+	state.Manifests.Add(&sous.Manifest{
+		Source: sous.SourceLocation{Repo: "gh"},
+		Owners: []string{"sam"},
+		Kind:   sous.ManifestKindService,
+	})
+	writer := graph.LocalStateWriter{StateWriter: sous.DummyStateManager{State: state}}
+
+	rm := RouteMap{{"manifest", "/manifest", &ManifestResource{}}}
+	gf := func() Injector {
+		g := psyringe.New(sous.SilentLogSet)
+		g.Add(state, writer)
+		return g
+	}
+	ts := httptest.NewServer(rm.BuildAuthRouter(gf, &Auth{Authenticator: ta}))
+	defer ts.Close()
+
+	del := func(token string) int {
+		res, err := http.Get(ts.URL + "/manifest?repo=gh")
+		require.NoError(err)
+		res.Body.Close()
+		rq, err := http.NewRequest("DELETE", ts.URL+"/manifest?repo=gh", nil)
+		require.NoError(err)
+		rq.Header.Set("If-Match", res.Header.Get("Etag"))
+		if token != "" {
+			rq.Header.Set("Authorization", "Bearer "+token)
+		}
+		res, err = http.DefaultClient.Do(rq)
+		require.NoError(err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	assert.Equal(401, del(""))
+	assert.Equal(401, del("wrong"))
+	assert.Equal(403, del("hunter2"))
+	assert.Equal(1, state.Manifests.Len())
+	assert.Equal(204, del("s3cret"))
+	assert.Equal(0, state.Manifests.Len())
+}
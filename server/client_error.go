@@ -0,0 +1,18 @@
+package server
+
+import "fmt"
+
+// A ClientError explains why a request was refused. Unlike other data
+// returned with an error status, it is rendered as the response body.
+type ClientError struct {
+	Message string
+	// Problems lists the individual things wrong with the request.
+	Problems []string `json:",omitempty"`
+}
+
+func (ce *ClientError) Error() string {
+	if len(ce.Problems) == 0 {
+		return ce.Message
+	}
+	return fmt.Sprintf("%s: %v", ce.Message, ce.Problems)
+}
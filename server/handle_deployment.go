@@ -0,0 +1,140 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+)
+
+type (
+	// DeploymentResource describes resources for single deployments, which
+	// are each a cluster's DeploySpec within a manifest.
+	DeploymentResource struct{}
+
+	// GETDeploymentHandler handles GET exchanges for deployments
+	GETDeploymentHandler struct {
+		*sous.State
+		*QueryValues
+	}
+
+	// PUTDeploymentHandler handles PUT exchanges for deployments
+	PUTDeploymentHandler struct {
+		*sous.State
+		*http.Request
+		*QueryValues
+		StateWriter graph.LocalStateWriter
+		Caller      *Caller
+	}
+
+	// DELETEDeploymentHandler handles DELETE exchanges for deployments
+	DELETEDeploymentHandler struct {
+		*sous.State
+		*QueryValues
+		StateWriter graph.LocalStateWriter
+		Caller      *Caller
+	}
+)
+
+// Get implements Getable for DeploymentResource
+func (dr *DeploymentResource) Get() Exchanger { return &GETDeploymentHandler{} }
+
+// Put implements Putable for DeploymentResource
+func (dr *DeploymentResource) Put() Exchanger { return &PUTDeploymentHandler{} }
+
+// Delete implements Deleteable for DeploymentResource
+func (dr *DeploymentResource) Delete() Exchanger { return &DELETEDeploymentHandler{} }
+
+// Exchange implements Exchanger
+func (gdh *GETDeploymentHandler) Exchange() (interface{}, int) {
+	did, err := deployIDFromValues(gdh.QueryValues)
+	if err != nil {
+		return err, http.StatusNotFound
+	}
+	d, there, err := gdh.State.Deployment(did)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	if !there {
+		return nil, http.StatusNotFound
+	}
+	return d, http.StatusOK
+}
+
+// Exchange implements Exchanger
+func (pdh *PUTDeploymentHandler) Exchange() (interface{}, int) {
+	did, err := deployIDFromValues(pdh.QueryValues)
+	if err != nil {
+		return err, http.StatusNotFound
+	}
+	if _, there := pdh.State.Manifests.Get(did.ManifestID); !there {
+		return &ClientError{Message: fmt.Sprintf("No manifest %q: PUT it to /manifest first", did.ManifestID)}, http.StatusNotFound
+	}
+
+	dec := json.NewDecoder(pdh.Request.Body)
+	d := &sous.Deployment{}
+	if err := dec.Decode(d); err != nil {
+		return &ClientError{Message: "Could not decode deployment: " + err.Error()}, http.StatusBadRequest
+	}
+	if d.ID() != did {
+		return &ClientError{Message: fmt.Sprintf("Deployment %q doesn't belong at %q", d.ID(), did)}, http.StatusBadRequest
+	}
+	if err := pdh.State.SetDeployment(d); err != nil {
+		return &ClientError{Message: err.Error()}, http.StatusBadRequest
+	}
+	m, _ := pdh.State.Manifests.Get(did.ManifestID)
+	if ce := validateManifest(pdh.State, m); ce != nil {
+		return ce, http.StatusBadRequest
+	}
+	if err := pdh.StateWriter.WriteState(pdh.State); err != nil {
+		return err, http.StatusConflict
+	}
+	d, _, err = pdh.State.Deployment(did)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	return d, http.StatusOK
+}
+
+// Exchange implements Exchanger
+func (ddh *DELETEDeploymentHandler) Exchange() (interface{}, int) {
+	did, err := deployIDFromValues(ddh.QueryValues)
+	if err != nil {
+		return err, http.StatusNotFound
+	}
+	if !ddh.State.DeleteDeployment(did) {
+		return nil, http.StatusNotFound
+	}
+	if err := ddh.StateWriter.WriteState(ddh.State); err != nil {
+		return err, http.StatusConflict
+	}
+	return nil, http.StatusNoContent
+}
+
+// deployIDFromValues builds a DeployID from the same values as
+// manifestIDFromValues, plus the name of the cluster.
+func deployIDFromValues(qv *QueryValues) (sous.DeployID, error) {
+	mid, err := manifestIDFromValues(qv)
+	if err != nil {
+		return sous.DeployID{}, err
+	}
+	c, err := qv.Single("cluster")
+	if err != nil {
+		return sous.DeployID{}, err
+	}
+	return sous.DeployID{ManifestID: mid, Cluster: c}, nil
+}
+
+// Authorize implements Authorizer: only the owners of the deployment's
+// manifest, and admins, may change it.
+func (pdh *PUTDeploymentHandler) Authorize() (*ClientError, int) {
+	return authorizeManifest(pdh.Caller, pdh.State, pdh.QueryValues)
+}
+
+// Authorize implements Authorizer: only the owners of the deployment's
+// manifest, and admins, may delete it.
+func (ddh *DELETEDeploymentHandler) Authorize() (*ClientError, int) {
+	return authorizeManifest(ddh.Caller, ddh.State, ddh.QueryValues)
+}
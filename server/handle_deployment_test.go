@@ -0,0 +1,166 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/samsalisbury/psyringe"
+	"github.com/samsalisbury/semv"
+)
+
+func deploymentTestState() *sous.State {
+	state := sous.NewState()
+	state.Defs.Clusters = sous.Clusters{
+		"one": &sous.Cluster{Name: "one", BaseURL: "http://one"},
+		"two": &sous.Cluster{Name: "two", BaseURL: "http://two"},
+	}
+	spec := func(instances int) sous.DeploySpec {
+		return sous.DeploySpec{
+			Version: semv.MustParse("1.0.0"),
+			DeployConfig: sous.DeployConfig{
+				Resources:    sous.Resources{"cpus": "1", "memory": "100", "ports": "1"},
+				NumInstances: instances,
+			},
+		}
+	}
+	state.Manifests.Add(&sous.Manifest{
+		Source: sous.SourceLocation{Repo: "gh"},
+		Kind:   sous.ManifestKindService,
+		Owners: []string{"sam"},
+		Deployments: sous.DeploySpecs{
+			"one": spec(1),
+			"two": spec(2),
+		},
+	})
+	return state
+}
+
+func deploymentQuery(t *testing.T, s string) *QueryValues {
+	q, err := url.ParseQuery(s)
+	require.NoError(t, err)
+	return &QueryValues{q}
+}
+
+func TestHandlesDeploymentGet(t *testing.T) {
+	assert := assert.New(t)
+
+	th := &GETDeploymentHandler{
+		State:       deploymentTestState(),
+		QueryValues: deploymentQuery(t, "repo=gh&cluster=two"),
+	}
+	data, status := th.Exchange()
+	assert.Equal(200, status)
+	if assert.IsType(&sous.Deployment{}, data) {
+		assert.Equal(2, data.(*sous.Deployment).NumInstances)
+	}
+
+	th.QueryValues = deploymentQuery(t, "repo=gh&cluster=three")
+	_, status = th.Exchange()
+	assert.Equal(404, status)
+
+	th.QueryValues = deploymentQuery(t, "repo=gh")
+	_, status = th.Exchange()
+	assert.Equal(404, status)
+}
+
+func TestHandlesDeploymentPut(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	state := deploymentTestState()
+	writer := graph.LocalStateWriter{StateWriter: sous.DummyStateManager{State: state}}
+	mid := sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}}
+
+	d, _, err := state.Deployment(sous.DeployID{ManifestID: mid, Cluster: "two"})
+	require.NoError(err)
+	d.SourceID.Version = semv.MustParse("2.0.0")
+
+	put := func(query string, d *sous.Deployment) (interface{}, int) {
+		buf := &bytes.Buffer{}
+		require.NoError(json.NewEncoder(buf).Encode(d))
+		req, err := http.NewRequest("PUT", "", buf)
+		require.NoError(err)
+		th := &PUTDeploymentHandler{
+			Request:     req,
+			StateWriter: writer,
+			State:       state,
+			QueryValues: deploymentQuery(t, query),
+		}
+		return th.Exchange()
+	}
+
+	_, status := put("repo=gh&cluster=one", d)
+	assert.Equal(400, status, "deployment for another cluster")
+
+	_, status = put("repo=other&cluster=two", d)
+	assert.Equal(404, status, "no such manifest")
+
+	data, status := put("repo=gh&cluster=two", d)
+	assert.Equal(200, status)
+	require.IsType(&sous.Deployment{}, data)
+	assert.Equal("2.0.0", data.(*sous.Deployment).SourceID.Version.String())
+
+	m, _ := state.Manifests.Get(mid)
+	assert.Equal("2.0.0", m.Deployments["two"].Version.String())
+	assert.Equal("1.0.0", m.Deployments["one"].Version.String())
+
+	d.Owners = sous.NewOwnerSet("judson")
+	data, status = put("repo=gh&cluster=two", d)
+	assert.Equal(400, status)
+	assert.IsType(&ClientError{}, data)
+}
+
+func TestHandlesDeploymentDelete(t *testing.T) {
+	assert := assert.New(t)
+
+	state := deploymentTestState()
+	writer := graph.LocalStateWriter{StateWriter: sous.DummyStateManager{State: state}}
+	th := &DELETEDeploymentHandler{
+		State:       state,
+		StateWriter: writer,
+		QueryValues: deploymentQuery(t, "repo=gh&cluster=one"),
+	}
+	_, status := th.Exchange()
+	assert.Equal(204, status)
+	_, status = th.Exchange()
+	assert.Equal(404, status)
+
+	m, _ := state.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}})
+	assert.Equal([]string{"two"}, m.ClusterNames())
+}
+
+func TestDeploymentDeleteNeedsCurrentEtag(t *testing.T) {
+	assert := assert.New(t)
+
+	state := deploymentTestState()
+	writer := graph.LocalStateWriter{StateWriter: sous.DummyStateManager{State: state}}
+	rm := RouteMap{{"deployment", "/deployment", &DeploymentResource{}}}
+	gf := func() Injector {
+		g := psyringe.New(sous.SilentLogSet)
+		g.Add(state, writer)
+		return g
+	}
+	ts := httptest.NewServer(rm.BuildRouter(gf))
+	defer ts.Close()
+
+	u := ts.URL + "/deployment?repo=gh&cluster=one"
+	etag := defsRequest(t, "GET", u, "", nil).Header.Get("Etag")
+	assert.NotEqual("", etag)
+
+	assert.Equal(http.StatusPreconditionRequired, defsRequest(t, "DELETE", u, "", nil).StatusCode)
+	assert.Equal(http.StatusPreconditionFailed, defsRequest(t, "DELETE", u, "stale", nil).StatusCode)
+	m, _ := state.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}})
+	assert.Equal([]string{"one", "two"}, m.ClusterNames())
+
+	assert.Equal(http.StatusNoContent, defsRequest(t, "DELETE", u, etag, nil).StatusCode)
+	m, _ = state.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}})
+	assert.Equal([]string{"two"}, m.ClusterNames())
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"sort"
+
 	"github.com/opentable/sous/graph"
 	"github.com/opentable/sous/lib"
 )
@@ -17,6 +19,8 @@ type (
 	gdmWrapper struct {
 		Deployments []*sous.Deployment
 	}
+
+	deploymentsByID []*sous.Deployment
 )
 
 // Get implements Getable on GDMResource
@@ -28,5 +32,17 @@ func (h *GDMHandler) Exchange() (interface{}, int) {
 	for _, d := range h.GDM.Snapshot() {
 		data.Deployments = append(data.Deployments, d)
 	}
+	// The order is fixed so that the GDM's Etag only changes with its content.
+	sort.Sort(deploymentsByID(data.Deployments))
 	return data, 200
 }
+
+func (ds deploymentsByID) Len() int      { return len(ds) }
+func (ds deploymentsByID) Swap(i, j int) { ds[i], ds[j] = ds[j], ds[i] }
+func (ds deploymentsByID) Less(i, j int) bool {
+	a, b := ds[i].ID(), ds[j].ID()
+	if a.Cluster != b.Cluster {
+		return a.Cluster < b.Cluster
+	}
+	return a.ManifestID.String() < b.ManifestID.String()
+}
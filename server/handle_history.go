@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/opentable/sous/lib"
+)
+
+type (
+	// HistoryResource is the resource for the resolve history
+	HistoryResource struct{}
+
+	// HistoryHandler is an injectable request handler
+	HistoryHandler struct {
+		History sous.ResolveHistory
+		*QueryValues
+	}
+
+	historyWrapper struct {
+		Resolves []*sous.ResolveRecord
+	}
+)
+
+// DefaultHistoryLimit is the number of resolves returned by /history when no
+// limit is given.
+const DefaultHistoryLimit = 20
+
+// Get implements Getable on HistoryResource
+func (hr *HistoryResource) Get() Exchanger { return &HistoryHandler{} }
+
+// Exchange implements the Handler interface
+func (h *HistoryHandler) Exchange() (interface{}, int) {
+	ls, err := h.QueryValues.Single("limit", strconv.Itoa(DefaultHistoryLimit))
+	if err != nil {
+		return err, http.StatusBadRequest
+	}
+	limit, err := strconv.Atoi(ls)
+	if err != nil {
+		return err, http.StatusBadRequest
+	}
+	recs, err := h.History.ResolveRecords(limit)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	return historyWrapper{Resolves: recs}, http.StatusOK
+}
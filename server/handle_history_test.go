@@ -0,0 +1,40 @@
+package server
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/opentable/sous/lib"
+)
+
+type testHistory struct {
+	limit int
+}
+
+func (th *testHistory) RecordResolve(*sous.ResolveRecord) error { return nil }
+
+func (th *testHistory) ResolveRecords(limit int) ([]*sous.ResolveRecord, error) {
+	th.limit = limit
+	return []*sous.ResolveRecord{{}}, nil
+}
+
+func TestHandlesHistoryGet(t *testing.T) {
+	assert := assert.New(t)
+
+	th := &testHistory{}
+	h := &HistoryHandler{History: th, QueryValues: &QueryValues{url.Values{}}}
+	data, status := h.Exchange()
+	assert.Equal(200, status)
+	assert.Equal(DefaultHistoryLimit, th.limit)
+	assert.Len(data.(historyWrapper).Resolves, 1)
+
+	h.QueryValues = &QueryValues{url.Values{"limit": {"5"}}}
+	_, status = h.Exchange()
+	assert.Equal(200, status)
+	assert.Equal(5, th.limit)
+
+	h.QueryValues = &QueryValues{url.Values{"limit": {"lots"}}}
+	_, status = h.Exchange()
+	assert.Equal(400, status)
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/opentable/sous/graph"
@@ -25,6 +26,7 @@ type (
 		*http.Request
 		*QueryValues
 		StateWriter graph.LocalStateWriter
+		Caller      *Caller
 	}
 
 	// DELETEManifestHandler handles DELETE exchanges for manifests
@@ -32,6 +34,7 @@ type (
 		*sous.State
 		*QueryValues
 		StateWriter graph.LocalStateWriter
+		Caller      *Caller
 	}
 )
 
@@ -81,10 +84,14 @@ func (pmh *PUTManifestHandler) Exchange() (interface{}, int) {
 
 	dec := json.NewDecoder(pmh.Request.Body)
 	m := &sous.Manifest{}
-	dec.Decode(m)
-	flaws := m.Validate()
-	if len(flaws) > 0 {
-		return "", http.StatusBadRequest
+	if err := dec.Decode(m); err != nil {
+		return &ClientError{Message: "Could not decode manifest: " + err.Error()}, http.StatusBadRequest
+	}
+	if m.ID() != mid {
+		return &ClientError{Message: fmt.Sprintf("Manifest %q doesn't belong at %q", m.ID(), mid)}, http.StatusBadRequest
+	}
+	if ce := validateManifest(pmh.State, m); ce != nil {
+		return ce, http.StatusBadRequest
 	}
 	pmh.State.Manifests.Set(mid, m)
 	if err := pmh.StateWriter.WriteState(pmh.State); err != nil {
@@ -93,6 +100,25 @@ func (pmh *PUTManifestHandler) Exchange() (interface{}, int) {
 	return m, http.StatusOK
 }
 
+// validateManifest checks m, and m against the definitions in state,
+// repairing what flaws it can. It returns a ClientError describing any that
+// remain.
+func validateManifest(state *sous.State, m *sous.Manifest) *ClientError {
+	flaws := append(m.Validate(), state.Defs.CheckManifest(m)...)
+	for _, f := range flaws {
+		f.AddContext("state", state)
+	}
+	_, errs := sous.RepairAll(flaws)
+	if len(errs) == 0 {
+		return nil
+	}
+	ce := &ClientError{Message: fmt.Sprintf("Manifest %q is invalid", m.ID())}
+	for _, err := range errs {
+		ce.Problems = append(ce.Problems, err.Error())
+	}
+	return ce
+}
+
 /*
 To recap:
 
@@ -126,3 +152,15 @@ func manifestIDFromValues(qv *QueryValues) (sous.ManifestID, error) {
 		Flavor: f,
 	}, nil
 }
+
+// Authorize implements Authorizer: only the manifest's owners, and admins,
+// may change it.
+func (pmh *PUTManifestHandler) Authorize() (*ClientError, int) {
+	return authorizeManifest(pmh.Caller, pmh.State, pmh.QueryValues)
+}
+
+// Authorize implements Authorizer: only the manifest's owners, and admins,
+// may delete it.
+func (dmh *DELETEManifestHandler) Authorize() (*ClientError, int) {
+	return authorizeManifest(dmh.Caller, dmh.State, dmh.QueryValues)
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+)
+
+type (
+	// ManifestHistoryResource describes the resource for the history of
+	// changes to a manifest.
+	ManifestHistoryResource struct{}
+
+	// GETManifestHistoryHandler handles GET exchanges for manifest history.
+	GETManifestHistoryHandler struct {
+		*QueryValues
+		StateManager *graph.StateManager
+	}
+
+	manifestHistoryWrapper struct {
+		Changes []sous.ManifestChange
+	}
+)
+
+// Get implements Getable on ManifestHistoryResource.
+func (mhr *ManifestHistoryResource) Get() Exchanger { return &GETManifestHistoryHandler{} }
+
+// Exchange implements Exchanger.
+func (gmh *GETManifestHistoryHandler) Exchange() (interface{}, int) {
+	mid, err := manifestIDFromValues(gmh.QueryValues)
+	if err != nil {
+		return &ClientError{Message: err.Error()}, http.StatusBadRequest
+	}
+	historian, ok := gmh.StateManager.StateManager.(sous.ManifestHistorian)
+	if !ok {
+		return &ClientError{Message: "This server's state has no history"}, http.StatusNotImplemented
+	}
+	changes, err := historian.ManifestHistory(mid)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	if len(changes) == 0 {
+		return nil, http.StatusNotFound
+	}
+	return manifestHistoryWrapper{Changes: changes}, http.StatusOK
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+)
+
+type historicalStateManager struct {
+	sous.DummyStateManager
+	changes map[sous.ManifestID][]sous.ManifestChange
+}
+
+func (hsm *historicalStateManager) ManifestHistory(mid sous.ManifestID) ([]sous.ManifestChange, error) {
+	return hsm.changes[mid], nil
+}
+
+func TestHandlesManifestHistoryGet(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mid := sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}}
+	sm := &historicalStateManager{changes: map[sous.ManifestID][]sous.ManifestChange{
+		mid: {{Revision: "abc", Author: "sam", Diffs: []string{"created"}}},
+	}}
+	handler := func(query string) *GETManifestHistoryHandler {
+		q, err := url.ParseQuery(query)
+		require.NoError(err)
+		return &GETManifestHistoryHandler{
+			QueryValues:  &QueryValues{q},
+			StateManager: &graph.StateManager{StateManager: sm},
+		}
+	}
+
+	data, status := handler("repo=gh").Exchange()
+	assert.Equal(200, status)
+	require.IsType(manifestHistoryWrapper{}, data)
+	assert.Equal("abc", data.(manifestHistoryWrapper).Changes[0].Revision)
+
+	_, status = handler("repo=other").Exchange()
+	assert.Equal(404, status)
+
+	_, status = handler("").Exchange()
+	assert.Equal(400, status)
+}
+
+func TestHandlesManifestHistoryWithoutHistory(t *testing.T) {
+	q, err := url.ParseQuery("repo=gh")
+	require.NoError(t, err)
+	th := &GETManifestHistoryHandler{
+		QueryValues:  &QueryValues{q},
+		StateManager: &graph.StateManager{StateManager: sous.DummyStateManager{State: sous.NewState()}},
+	}
+	_, status := th.Exchange()
+	assert.Equal(t, 501, status)
+}
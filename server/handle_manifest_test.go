@@ -153,3 +153,78 @@ func TestHandlesManifestPut(t *testing.T) {
 	assert.Equal(changed.Owners[1], "judson")
 
 }
+
+func TestHandlesManifestPutInvalid(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	q, err := url.ParseQuery("repo=gh")
+	require.NoError(err)
+	state := sous.NewState()
+	state.Defs.EnvVars = sous.EnvDefs{
+		{Name: "CLUSTER_NAME", Scope: sous.EnvScopeCluster},
+	}
+	writer := graph.LocalStateWriter{StateWriter: sous.DummyStateManager{State: state}}
+
+	manifest := &sous.Manifest{
+		Source: sous.SourceLocation{Repo: "gh"},
+		Kind:   sous.ManifestKindService,
+		Deployments: sous.DeploySpecs{
+			"cluster": {DeployConfig: sous.DeployConfig{
+				Resources: sous.Resources{"cpus": "1", "memory": "100", "ports": "1"},
+				Env:       sous.Env{"CLUSTER_NAME": "mine"},
+			}},
+		},
+	}
+	buf := &bytes.Buffer{}
+	json.NewEncoder(buf).Encode(manifest)
+	req, err := http.NewRequest("PUT", "", buf)
+	require.NoError(err)
+
+	th := &PUTManifestHandler{
+		Request:     req,
+		StateWriter: writer,
+		State:       state,
+		QueryValues: &QueryValues{q},
+	}
+	data, status := th.Exchange()
+	assert.Equal(400, status)
+	require.IsType(&ClientError{}, data)
+	problems := data.(*ClientError).Problems
+	require.Len(problems, 1)
+	assert.Contains(problems[0], "CLUSTER_NAME")
+
+	_, found := state.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}})
+	assert.False(found)
+}
+
+func TestHandlesManifestPutMismatchedID(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	q, err := url.ParseQuery("repo=gh")
+	require.NoError(err)
+	state := sous.NewState()
+	writer := graph.LocalStateWriter{StateWriter: sous.DummyStateManager{State: state}}
+
+	manifest := &sous.Manifest{
+		Source: sous.SourceLocation{Repo: "elsewhere"},
+		Kind:   sous.ManifestKindService,
+	}
+	buf := &bytes.Buffer{}
+	json.NewEncoder(buf).Encode(manifest)
+	req, err := http.NewRequest("PUT", "", buf)
+	require.NoError(err)
+
+	th := &PUTManifestHandler{
+		Request:     req,
+		StateWriter: writer,
+		State:       state,
+		QueryValues: &QueryValues{q},
+	}
+	data, status := th.Exchange()
+	assert.Equal(400, status)
+	assert.IsType(&ClientError{}, data)
+
+	assert.Equal(0, state.Manifests.Len())
+}
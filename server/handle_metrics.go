@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/opentable/sous/util/metrics"
+)
+
+type (
+	// MetricsResource is the resource for the server's Prometheus metrics
+	MetricsResource struct{}
+
+	// MetricsHandler is an injectable request handler
+	MetricsHandler struct{}
+
+	metricsBody struct {
+		*metrics.Registry
+	}
+)
+
+// Get implements Getable on MetricsResource
+func (mr *MetricsResource) Get() Exchanger { return &MetricsHandler{} }
+
+// Exchange implements the Handler interface
+func (h *MetricsHandler) Exchange() (interface{}, int) {
+	return metricsBody{metrics.Default}, http.StatusOK
+}
+
+// ContentType implements RawBody on metricsBody
+func (metricsBody) ContentType() string { return "text/plain; version=0.0.4" }
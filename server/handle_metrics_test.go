@@ -0,0 +1,42 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/lib"
+	"github.com/samsalisbury/psyringe"
+)
+
+func TestMetricsGet(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	rm := RouteMap{
+		{"test", "/test/:param", &TestResource{"base"}},
+		{"metrics", "/metrics", &MetricsResource{}},
+	}
+	gf := func() Injector { return psyringe.New(sous.SilentLogSet) }
+	ts := httptest.NewServer(rm.BuildRouter(gf))
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/test/missing")
+	require.NoError(err)
+	res.Body.Close()
+
+	res, err = http.Get(ts.URL + "/metrics")
+	require.NoError(err)
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(err)
+
+	assert.Equal(200, res.StatusCode)
+	assert.Equal("text/plain; version=0.0.4", res.Header.Get("Content-Type"))
+	assert.Contains(string(body), "# TYPE sous_http_responses_total counter")
+	assert.Contains(string(body), `sous_http_responses_total{route="test",method="GET",code="404"}`)
+	assert.Contains(string(body), "# TYPE sous_resolve_duration_seconds histogram")
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+)
+
+type (
+	// RollbacksResource describes the resource for the state's rollbacks,
+	// which record versions that failed to roll out, so that they aren't
+	// deployed again.
+	RollbacksResource struct{}
+
+	// RollbacksGetHandler handles GET exchanges for rollbacks.
+	RollbacksGetHandler struct {
+		*sous.State
+	}
+
+	// RollbacksPutHandler handles PUT exchanges for rollbacks.
+	RollbacksPutHandler struct {
+		*sous.State
+		*http.Request
+		StateWriter graph.LocalStateWriter
+		Caller      *Caller
+		data        *rollbacksWrapper
+	}
+
+	rollbacksWrapper struct {
+		Rollbacks sous.Rollbacks
+	}
+)
+
+// Get implements Getable on RollbacksResource.
+func (rr *RollbacksResource) Get() Exchanger { return &RollbacksGetHandler{} }
+
+// Put implements Putable on RollbacksResource.
+func (rr *RollbacksResource) Put() Exchanger { return &RollbacksPutHandler{} }
+
+// Exchange implements Exchanger.
+func (rg *RollbacksGetHandler) Exchange() (interface{}, int) {
+	data := rollbacksWrapper{Rollbacks: rg.State.Rollbacks}
+	if data.Rollbacks == nil {
+		data.Rollbacks = sous.Rollbacks{}
+	}
+	return data, http.StatusOK
+}
+
+// Authorize implements Authorizer: only the owners of a deployment's
+// manifest, and admins, may add, remove or change its rollback.
+func (rp *RollbacksPutHandler) Authorize() (*ClientError, int) {
+	data, ce := rp.decode()
+	if ce != nil {
+		return ce, http.StatusBadRequest
+	}
+	return authorizeDeployments(rp.Caller, rp.State, "rollback", changedRollbacks(rp.State.Rollbacks, data.Rollbacks))
+}
+
+// Exchange implements Exchanger.
+func (rp *RollbacksPutHandler) Exchange() (interface{}, int) {
+	data, ce := rp.decode()
+	if ce != nil {
+		return ce, http.StatusBadRequest
+	}
+	rp.State.Rollbacks = data.Rollbacks
+	if err := rp.StateWriter.WriteState(rp.State); err != nil {
+		return err, http.StatusConflict
+	}
+	return data, http.StatusOK
+}
+
+// decode reads the rollbacks from the request, once, for both Authorize and
+// Exchange.
+func (rp *RollbacksPutHandler) decode() (*rollbacksWrapper, *ClientError) {
+	if rp.data == nil {
+		data := &rollbacksWrapper{}
+		if err := json.NewDecoder(rp.Request.Body).Decode(data); err != nil {
+			return nil, &ClientError{Message: "Could not decode rollbacks: " + err.Error()}
+		}
+		rp.data = data
+	}
+	return rp.data, nil
+}
+
+// changedRollbacks returns the deployments whose rollbacks differ between old
+// and new, including those only in one of them.
+func changedRollbacks(old, new sous.Rollbacks) []sous.DeployID {
+	var ids []sous.DeployID
+	for _, n := range new {
+		o, there := old.Get(n.Deployment)
+		if !there || o.Version.String() != n.Version.String() || !o.At.Equal(n.At) || o.Reason != n.Reason {
+			ids = append(ids, n.Deployment)
+		}
+	}
+	for _, o := range old {
+		if _, there := new.Get(o.Deployment); !there {
+			ids = append(ids, o.Deployment)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/samsalisbury/psyringe"
+	"github.com/samsalisbury/semv"
+)
+
+func TestRollbacksPutAndGet(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	state := sous.NewState()
+	writer := graph.LocalStateWriter{StateWriter: sous.DummyStateManager{State: state}}
+	rm := RouteMap{{"rollbacks", "/rollbacks", &RollbacksResource{}}}
+	gf := func() Injector {
+		g := psyringe.New(sous.SilentLogSet)
+		g.Add(state, writer)
+		return g
+	}
+	ts := httptest.NewServer(rm.BuildRouter(gf))
+	defer ts.Close()
+
+	res := defsRequest(t, "GET", ts.URL+"/rollbacks", "", nil)
+	require.Equal(200, res.StatusCode)
+	etag := res.Header.Get("Etag")
+
+	id := sous.DeployID{ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}}, Cluster: "a"}
+	body := rollbacksWrapper{Rollbacks: sous.Rollbacks{{Deployment: id, Version: semv.MustParse("2.0.0"), At: time.Now()}}}
+	assert.Equal(http.StatusPreconditionRequired, defsRequest(t, "PUT", ts.URL+"/rollbacks", "", body).StatusCode)
+	assert.Equal(200, defsRequest(t, "PUT", ts.URL+"/rollbacks", etag, body).StatusCode)
+	require.Len(state.Rollbacks, 1)
+	assert.Equal(id, state.Rollbacks[0].Deployment)
+
+	res, err := http.Get(ts.URL + "/rollbacks")
+	require.NoError(err)
+	data := rollbacksWrapper{}
+	require.NoError(json.NewDecoder(res.Body).Decode(&data))
+	res.Body.Close()
+	require.Len(data.Rollbacks, 1)
+	assert.Equal(id, data.Rollbacks[0].Deployment)
+}
+
+func TestRollbacksPutChecksManifestOwners(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	state, put, done := ownedStateServer(t, RouteMap{{"rollbacks", "/rollbacks", &RollbacksResource{}}})
+	defer done()
+
+	sams := sous.DeployID{ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}}, Cluster: "a"}
+	at := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+	rollback := &sous.Rollback{Deployment: sams, Version: semv.MustParse("2.0.0"), At: at}
+
+	assert.Equal(403, put("hunter2", "/rollbacks", rollbacksWrapper{Rollbacks: sous.Rollbacks{rollback}}))
+	assert.Len(state.Rollbacks, 0)
+	assert.Equal(200, put("s3cret", "/rollbacks", rollbacksWrapper{Rollbacks: sous.Rollbacks{rollback}}))
+	require.Len(state.Rollbacks, 1)
+	assert.Equal(200, put("hunter2", "/rollbacks", rollbacksWrapper{Rollbacks: sous.Rollbacks{rollback}}))
+	assert.Equal(403, put("hunter2", "/rollbacks", rollbacksWrapper{}))
+	assert.Len(state.Rollbacks, 1)
+}
@@ -1,17 +1,192 @@
 package server
 
-import "github.com/opentable/sous/lib"
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+)
 
 type (
+	// StateDefResource describes the resource for the state's Defs.
 	StateDefResource struct{}
 
+	// StateDefGetHandler handles GET exchanges for defs.
 	StateDefGetHandler struct {
 		*sous.State
 	}
+
+	// StateDefPutHandler handles PUT exchanges for defs.
+	StateDefPutHandler struct {
+		*sous.State
+		*http.Request
+		StateWriter graph.LocalStateWriter
+		Caller      *Caller
+	}
+
+	// ClusterDefResource describes resources for single clusters in the
+	// state's Defs.
+	ClusterDefResource struct{}
+
+	// ClusterDefGetHandler handles GET exchanges for cluster definitions.
+	ClusterDefGetHandler struct {
+		*sous.State
+		*QueryValues
+	}
+
+	// ClusterDefPutHandler handles PUT exchanges for cluster definitions.
+	ClusterDefPutHandler struct {
+		*sous.State
+		*http.Request
+		*QueryValues
+		StateWriter graph.LocalStateWriter
+		Caller      *Caller
+	}
+
+	// ClusterDefDeleteHandler handles DELETE exchanges for cluster
+	// definitions.
+	ClusterDefDeleteHandler struct {
+		*sous.State
+		*QueryValues
+		StateWriter graph.LocalStateWriter
+		Caller      *Caller
+	}
 )
 
+// Get implements Getable on StateDefResource.
 func (sdr *StateDefResource) Get() Exchanger { return &StateDefGetHandler{} }
 
+// Put implements Putable on StateDefResource.
+func (sdr *StateDefResource) Put() Exchanger { return &StateDefPutHandler{} }
+
+// Exchange implements Exchanger.
 func (sdg *StateDefGetHandler) Exchange() (interface{}, int) {
 	return sdg.State.Defs, 200
 }
+
+// Authorize implements Authorizer: only admins may change defs.
+func (sdp *StateDefPutHandler) Authorize() (*ClientError, int) {
+	return sdp.Caller.MayAdminister("defs")
+}
+
+// Exchange implements Exchanger.
+func (sdp *StateDefPutHandler) Exchange() (interface{}, int) {
+	defs := sous.Defs{}
+	if err := json.NewDecoder(sdp.Request.Body).Decode(&defs); err != nil {
+		return &ClientError{Message: "Could not decode defs: " + err.Error()}, http.StatusBadRequest
+	}
+	changed := sdp.State.Clone()
+	if err := changed.SetDefs(defs); err != nil {
+		return &ClientError{Message: err.Error()}, http.StatusConflict
+	}
+	if ce := validateDefs(sdp.State, changed); ce != nil {
+		return ce, http.StatusBadRequest
+	}
+	sdp.State.Defs = changed.Defs
+	if err := sdp.StateWriter.WriteState(sdp.State); err != nil {
+		return err, http.StatusConflict
+	}
+	return sdp.State.Defs, http.StatusOK
+}
+
+// Get implements Getable on ClusterDefResource.
+func (cdr *ClusterDefResource) Get() Exchanger { return &ClusterDefGetHandler{} }
+
+// Put implements Putable on ClusterDefResource.
+func (cdr *ClusterDefResource) Put() Exchanger { return &ClusterDefPutHandler{} }
+
+// Delete implements Deleteable on ClusterDefResource.
+func (cdr *ClusterDefResource) Delete() Exchanger { return &ClusterDefDeleteHandler{} }
+
+// Exchange implements Exchanger.
+func (cdg *ClusterDefGetHandler) Exchange() (interface{}, int) {
+	name, err := cdg.QueryValues.Single("name")
+	if err != nil {
+		return err, http.StatusNotFound
+	}
+	cluster, ok := cdg.State.Defs.Clusters[name]
+	if !ok {
+		return nil, http.StatusNotFound
+	}
+	return cluster, http.StatusOK
+}
+
+// Authorize implements Authorizer: only admins may change clusters.
+func (cdp *ClusterDefPutHandler) Authorize() (*ClientError, int) {
+	return cdp.Caller.MayAdminister("clusters")
+}
+
+// Exchange implements Exchanger.
+func (cdp *ClusterDefPutHandler) Exchange() (interface{}, int) {
+	name, err := cdp.QueryValues.Single("name")
+	if err != nil {
+		return err, http.StatusNotFound
+	}
+	cluster := &sous.Cluster{}
+	if err := json.NewDecoder(cdp.Request.Body).Decode(cluster); err != nil {
+		return &ClientError{Message: "Could not decode cluster: " + err.Error()}, http.StatusBadRequest
+	}
+	changed := cdp.State.Clone()
+	if err := changed.SetCluster(name, cluster); err != nil {
+		return &ClientError{Message: err.Error()}, http.StatusBadRequest
+	}
+	if ce := validateDefs(cdp.State, changed); ce != nil {
+		return ce, http.StatusBadRequest
+	}
+	cdp.State.Defs = changed.Defs
+	if err := cdp.StateWriter.WriteState(cdp.State); err != nil {
+		return err, http.StatusConflict
+	}
+	return cluster, http.StatusOK
+}
+
+// Authorize implements Authorizer: only admins may delete clusters.
+func (cdd *ClusterDefDeleteHandler) Authorize() (*ClientError, int) {
+	return cdd.Caller.MayAdminister("clusters")
+}
+
+// Exchange implements Exchanger.
+func (cdd *ClusterDefDeleteHandler) Exchange() (interface{}, int) {
+	name, err := cdd.QueryValues.Single("name")
+	if err != nil {
+		return err, http.StatusNotFound
+	}
+	if _, ok := cdd.State.Defs.Clusters[name]; !ok {
+		return nil, http.StatusNotFound
+	}
+	if err := cdd.State.DeleteCluster(name); err != nil {
+		return &ClientError{Message: err.Error()}, http.StatusConflict
+	}
+	if err := cdd.StateWriter.WriteState(cdd.State); err != nil {
+		return err, http.StatusConflict
+	}
+	return nil, http.StatusNoContent
+}
+
+// validateDefs checks the manifests in changed against its definitions. It
+// returns a ClientError describing the flaws which can't be repaired, and which
+// prior, the state before the change, doesn't already have. Both states are
+// cloned, so that repairs made while validating them aren't kept.
+func validateDefs(prior, changed *sous.State) *ClientError {
+	known := map[string]bool{}
+	for _, err := range unrepairable(prior) {
+		known[err.Error()] = true
+	}
+	var problems []string
+	for _, err := range unrepairable(changed) {
+		if !known[err.Error()] {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ClientError{Message: "Defs are invalid", Problems: problems}
+}
+
+// unrepairable returns the errors from repairing the flaws of a clone of state.
+func unrepairable(state *sous.State) []error {
+	_, errs := sous.RepairAll(state.Clone().Validate())
+	return errs
+}
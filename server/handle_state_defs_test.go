@@ -1,9 +1,17 @@
 package server
 
 import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/nyarly/testify/assert"
+	"github.com/opentable/sous/graph"
 	"github.com/opentable/sous/lib"
+	"github.com/samsalisbury/psyringe"
 )
 
 func TestStateDefGet(t *testing.T) {
@@ -29,3 +37,189 @@ func TestStateDefGet(t *testing.T) {
 		t.Errorf("returned data wasn't a sous.Defs: %T", defs)
 	}
 }
+
+func TestStateDefGetNotModified(t *testing.T) {
+	state := &sous.State{Defs: sous.Defs{DockerRepo: "reponame"}}
+	rm := RouteMap{{"defs", "/defs", &StateDefResource{}}}
+	gf := func() Injector {
+		g := psyringe.New(sous.SilentLogSet)
+		g.Add(state)
+		return g
+	}
+	ts := httptest.NewServer(rm.BuildRouter(gf))
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/defs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	etag := res.Header.Get("Etag")
+	if res.StatusCode != 200 || etag == "" {
+		t.Fatalf("Got status %d and Etag %q, want 200 and an Etag", res.StatusCode, etag)
+	}
+
+	rq, err := http.NewRequest("GET", ts.URL+"/defs", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rq.Header.Set("If-None-Match", etag)
+	res, err = http.DefaultClient.Do(rq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusNotModified || len(body) != 0 {
+		t.Errorf("Got status %d and %d byte body, want 304 and no body", res.StatusCode, len(body))
+	}
+}
+
+func defsTestServer(t *testing.T, state *sous.State) *httptest.Server {
+	writer := graph.LocalStateWriter{StateWriter: sous.DummyStateManager{State: state}}
+	rm := RouteMap{
+		{"defs", "/defs", &StateDefResource{}},
+		{"cluster", "/defs/cluster", &ClusterDefResource{}},
+	}
+	gf := func() Injector {
+		g := psyringe.New(sous.SilentLogSet)
+		g.Add(state, writer)
+		return g
+	}
+	return httptest.NewServer(rm.BuildRouter(gf))
+}
+
+func defsTestState() *sous.State {
+	state := sous.NewState()
+	state.Defs.Clusters = sous.Clusters{
+		"used":   {Name: "used", BaseURL: "http://used"},
+		"unused": {Name: "unused", BaseURL: "http://unused"},
+	}
+	state.Manifests.Add(&sous.Manifest{
+		Source: sous.SourceLocation{Repo: "gh"},
+		Kind:   sous.ManifestKindService,
+		Deployments: sous.DeploySpecs{
+			"used": {DeployConfig: sous.DeployConfig{
+				Resources: sous.Resources{"cpus": "1", "memory": "100", "ports": "1"},
+			}},
+		},
+	})
+	return state
+}
+
+func defsRequest(t *testing.T, method, url, etag string, body interface{}) *http.Response {
+	buf := &bytes.Buffer{}
+	if body != nil {
+		json.NewEncoder(buf).Encode(body)
+	}
+	rq, err := http.NewRequest(method, url, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if etag != "" {
+		rq.Header.Set("If-Match", etag)
+	}
+	res, err := http.DefaultClient.Do(rq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	return res
+}
+
+func TestStateDefPut(t *testing.T) {
+	assert := assert.New(t)
+
+	state := defsTestState()
+	ts := defsTestServer(t, state)
+	defer ts.Close()
+
+	etag := defsRequest(t, "GET", ts.URL+"/defs", "", nil).Header.Get("Etag")
+
+	defs := state.Defs.Clone()
+	defs.DockerRepo = "docker.example.com"
+	assert.Equal(http.StatusPreconditionRequired, defsRequest(t, "PUT", ts.URL+"/defs", "", defs).StatusCode)
+	assert.Equal(http.StatusPreconditionFailed, defsRequest(t, "PUT", ts.URL+"/defs", "stale", defs).StatusCode)
+	assert.Equal(200, defsRequest(t, "PUT", ts.URL+"/defs", etag, defs).StatusCode)
+	assert.Equal("docker.example.com", state.Defs.DockerRepo)
+
+	etag = defsRequest(t, "GET", ts.URL+"/defs", "", nil).Header.Get("Etag")
+	delete(defs.Clusters, "used")
+	assert.Equal(http.StatusConflict, defsRequest(t, "PUT", ts.URL+"/defs", etag, defs).StatusCode)
+	assert.Contains(state.Defs.Clusters, "used")
+
+	defs = state.Defs.Clone()
+	defs.EnvVars = sous.EnvDefs{{Name: "REGION", Scope: "nonsense"}}
+	assert.Equal(http.StatusBadRequest, defsRequest(t, "PUT", ts.URL+"/defs", etag, defs).StatusCode)
+	assert.Len(state.Defs.EnvVars, 0)
+}
+
+func TestStateDefPutIgnoresExistingFlaws(t *testing.T) {
+	assert := assert.New(t)
+
+	state := defsTestState()
+	state.Defs.EnvVars = sous.EnvDefs{{Name: "CLUSTER_NAME", Scope: sous.EnvScopeCluster}}
+	m, _ := state.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}})
+	spec := m.Deployments["used"]
+	spec.Env = sous.Env{"CLUSTER_NAME": "mine"}
+	m.Deployments["used"] = spec
+	ts := defsTestServer(t, state)
+	defer ts.Close()
+
+	etag := defsRequest(t, "GET", ts.URL+"/defs", "", nil).Header.Get("Etag")
+	defs := state.Defs.Clone()
+	defs.DockerRepo = "docker.example.com"
+	assert.Equal(200, defsRequest(t, "PUT", ts.URL+"/defs", etag, defs).StatusCode)
+	assert.Equal("docker.example.com", state.Defs.DockerRepo)
+
+	m, _ = state.Manifests.Get(sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}})
+	assert.Equal("mine", m.Deployments["used"].Env["CLUSTER_NAME"])
+}
+
+func TestClusterDefDelete(t *testing.T) {
+	assert := assert.New(t)
+
+	state := defsTestState()
+	ts := defsTestServer(t, state)
+	defer ts.Close()
+
+	res := defsRequest(t, "GET", ts.URL+"/defs/cluster?name=used", "", nil)
+	assert.Equal(200, res.StatusCode)
+	assert.Equal(http.StatusConflict, defsRequest(t, "DELETE", ts.URL+"/defs/cluster?name=used", res.Header.Get("Etag"), nil).StatusCode)
+	assert.Contains(state.Defs.Clusters, "used")
+
+	etag := defsRequest(t, "GET", ts.URL+"/defs/cluster?name=unused", "", nil).Header.Get("Etag")
+	assert.Equal(http.StatusPreconditionRequired, defsRequest(t, "DELETE", ts.URL+"/defs/cluster?name=unused", "", nil).StatusCode)
+	assert.Equal(http.StatusPreconditionFailed, defsRequest(t, "DELETE", ts.URL+"/defs/cluster?name=unused", "stale", nil).StatusCode)
+	assert.Contains(state.Defs.Clusters, "unused")
+	assert.Equal(http.StatusNoContent, defsRequest(t, "DELETE", ts.URL+"/defs/cluster?name=unused", etag, nil).StatusCode)
+	assert.NotContains(state.Defs.Clusters, "unused")
+	assert.Equal(http.StatusPreconditionFailed, defsRequest(t, "DELETE", ts.URL+"/defs/cluster?name=unused", etag, nil).StatusCode)
+}
+
+func TestClusterDefPut(t *testing.T) {
+	assert := assert.New(t)
+
+	state := defsTestState()
+	ts := defsTestServer(t, state)
+	defer ts.Close()
+
+	rq, err := http.NewRequest("PUT", ts.URL+"/defs/cluster?name=new", bytes.NewBufferString(`{"BaseURL": "http://new"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rq.Header.Set("If-None-Match", "*")
+	res, err := http.DefaultClient.Do(rq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	assert.Equal(200, res.StatusCode)
+	if assert.Contains(state.Defs.Clusters, "new") {
+		assert.Equal("http://new", state.Defs.Clusters["new"].BaseURL)
+		assert.Equal("new", state.Defs.Clusters["new"].Name)
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/opentable/sous/lib"
+)
+
+type (
+	// ResolveLoop is the AutoResolver that sous server runs.
+	ResolveLoop struct {
+		*sous.AutoResolver
+	}
+
+	// StatusResource is the resource for the server's auto-resolve status
+	StatusResource struct{}
+
+	// ResolveStatusHandler is an injectable request handler
+	ResolveStatusHandler struct {
+		*ResolveLoop
+	}
+
+	// ResolveResource is the resource that triggers a resolve when POSTed to
+	ResolveResource struct{}
+
+	// ResolveHandler is an injectable request handler
+	ResolveHandler struct {
+		*ResolveLoop
+		Caller *Caller
+	}
+)
+
+// Get implements Getable on StatusResource
+func (sr *StatusResource) Get() Exchanger { return &ResolveStatusHandler{} }
+
+// Exchange implements the Handler interface
+func (h *ResolveStatusHandler) Exchange() (interface{}, int) {
+	if h.ResolveLoop == nil {
+		return notResolving(), http.StatusServiceUnavailable
+	}
+	return h.Status(), http.StatusOK
+}
+
+// Post implements Postable on ResolveResource
+func (rr *ResolveResource) Post() Exchanger { return &ResolveHandler{} }
+
+// Authorize implements Authorizer: only admins may trigger resolves.
+func (h *ResolveHandler) Authorize() (*ClientError, int) {
+	return h.Caller.MayAdminister("resolves")
+}
+
+// Exchange implements the Handler interface
+func (h *ResolveHandler) Exchange() (interface{}, int) {
+	if h.ResolveLoop == nil {
+		return notResolving(), http.StatusServiceUnavailable
+	}
+	h.Trigger()
+	return h.Status(), http.StatusAccepted
+}
+
+func notResolving() *ClientError {
+	return &ClientError{Message: "this server isn't running resolves"}
+}
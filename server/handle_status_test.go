@@ -0,0 +1,55 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/opentable/sous/lib"
+)
+
+func TestResolveStatusGet(t *testing.T) {
+	assert := assert.New(t)
+
+	h := &ResolveStatusHandler{}
+	data, status := h.Exchange()
+	assert.Equal(503, status)
+	assert.IsType(&ClientError{}, data)
+
+	rez := sous.NewResolver(sous.NewDummyDeployer(), sous.NewDummyRegistry(), &sous.ResolveFilter{Cluster: "owned"})
+	ar := sous.NewAutoResolver(rez, &sous.DummyStateManager{State: sous.NewState()}, sous.SilentLogSet())
+	h.ResolveLoop = &ResolveLoop{ar}
+	data, status = h.Exchange()
+	assert.Equal(200, status)
+	if assert.IsType(sous.AutoResolveStatus{}, data) {
+		assert.Equal("owned", data.(sous.AutoResolveStatus).Filter.Cluster)
+	}
+}
+
+func TestResolvePost(t *testing.T) {
+	assert := assert.New(t)
+
+	h := &ResolveHandler{}
+	_, status := h.Exchange()
+	assert.Equal(503, status)
+
+	rez := sous.NewResolver(sous.NewDummyDeployer(), sous.NewDummyRegistry(), &sous.ResolveFilter{})
+	ar := sous.NewAutoResolver(rez, &sous.DummyStateManager{State: sous.NewState()}, sous.SilentLogSet())
+	h.ResolveLoop = &ResolveLoop{ar}
+	_, status = h.Exchange()
+	assert.Equal(202, status)
+}
+
+func TestResolvePostAuthorize(t *testing.T) {
+	assert := assert.New(t)
+
+	auth := &Auth{Admins: []string{"root"}}
+	status := func(c *Caller) int {
+		_, s := (&ResolveHandler{Caller: c}).Authorize()
+		return s
+	}
+
+	assert.Equal(200, status(&Caller{}))
+	assert.Equal(401, status(&Caller{Auth: auth}))
+	assert.Equal(403, status(&Caller{&Identity{"sam"}, auth}))
+	assert.Equal(200, status(&Caller{&Identity{"root"}, auth}))
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+)
+
+type (
+	// TombstonesResource describes the resource for the state's tombstones,
+	// which record deployments waiting out their cluster's grace period.
+	TombstonesResource struct{}
+
+	// TombstonesGetHandler handles GET exchanges for tombstones.
+	TombstonesGetHandler struct {
+		*sous.State
+	}
+
+	// TombstonesPutHandler handles PUT exchanges for tombstones.
+	TombstonesPutHandler struct {
+		*sous.State
+		*http.Request
+		StateWriter graph.LocalStateWriter
+		Caller      *Caller
+		data        *tombstonesWrapper
+	}
+
+	tombstonesWrapper struct {
+		Tombstones sous.Tombstones
+	}
+)
+
+// Get implements Getable on TombstonesResource.
+func (tr *TombstonesResource) Get() Exchanger { return &TombstonesGetHandler{} }
+
+// Put implements Putable on TombstonesResource.
+func (tr *TombstonesResource) Put() Exchanger { return &TombstonesPutHandler{} }
+
+// Exchange implements Exchanger.
+func (tg *TombstonesGetHandler) Exchange() (interface{}, int) {
+	data := tombstonesWrapper{Tombstones: tg.State.Tombstones}
+	if data.Tombstones == nil {
+		data.Tombstones = sous.Tombstones{}
+	}
+	return data, http.StatusOK
+}
+
+// Authorize implements Authorizer: only the owners of a deployment's
+// manifest, and admins, may add, remove or change its tombstone.
+func (tp *TombstonesPutHandler) Authorize() (*ClientError, int) {
+	data, ce := tp.decode()
+	if ce != nil {
+		return ce, http.StatusBadRequest
+	}
+	return authorizeDeployments(tp.Caller, tp.State, "tombstone", changedTombstones(tp.State.Tombstones, data.Tombstones))
+}
+
+// Exchange implements Exchanger.
+func (tp *TombstonesPutHandler) Exchange() (interface{}, int) {
+	data, ce := tp.decode()
+	if ce != nil {
+		return ce, http.StatusBadRequest
+	}
+	tp.State.Tombstones = data.Tombstones
+	if err := tp.StateWriter.WriteState(tp.State); err != nil {
+		return err, http.StatusConflict
+	}
+	return data, http.StatusOK
+}
+
+// decode reads the tombstones from the request, once, for both Authorize and
+// Exchange.
+func (tp *TombstonesPutHandler) decode() (*tombstonesWrapper, *ClientError) {
+	if tp.data == nil {
+		data := &tombstonesWrapper{}
+		if err := json.NewDecoder(tp.Request.Body).Decode(data); err != nil {
+			return nil, &ClientError{Message: "Could not decode tombstones: " + err.Error()}
+		}
+		tp.data = data
+	}
+	return tp.data, nil
+}
+
+// changedTombstones returns the deployments whose tombstones differ between
+// old and new, including those only in one of them.
+func changedTombstones(old, new sous.Tombstones) []sous.DeployID {
+	var ids []sous.DeployID
+	for _, n := range new {
+		if o, there := old.Get(n.Deployment); !there || !o.Since.Equal(n.Since) {
+			ids = append(ids, n.Deployment)
+		}
+	}
+	for _, o := range old {
+		if _, there := new.Get(o.Deployment); !there {
+			ids = append(ids, o.Deployment)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/samsalisbury/psyringe"
+)
+
+func TestTombstonesPutAndGet(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	state := sous.NewState()
+	writer := graph.LocalStateWriter{StateWriter: sous.DummyStateManager{State: state}}
+	rm := RouteMap{{"tombstones", "/tombstones", &TombstonesResource{}}}
+	gf := func() Injector {
+		g := psyringe.New(sous.SilentLogSet)
+		g.Add(state, writer)
+		return g
+	}
+	ts := httptest.NewServer(rm.BuildRouter(gf))
+	defer ts.Close()
+
+	res := defsRequest(t, "GET", ts.URL+"/tombstones", "", nil)
+	require.Equal(200, res.StatusCode)
+	etag := res.Header.Get("Etag")
+
+	id := sous.DeployID{ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}}, Cluster: "grace"}
+	body := tombstonesWrapper{Tombstones: sous.Tombstones{{Deployment: id, Since: time.Now()}}}
+	assert.Equal(http.StatusPreconditionRequired, defsRequest(t, "PUT", ts.URL+"/tombstones", "", body).StatusCode)
+	assert.Equal(200, defsRequest(t, "PUT", ts.URL+"/tombstones", etag, body).StatusCode)
+	require.Len(state.Tombstones, 1)
+	assert.Equal(id, state.Tombstones[0].Deployment)
+
+	res, err := http.Get(ts.URL + "/tombstones")
+	require.NoError(err)
+	data := tombstonesWrapper{}
+	require.NoError(json.NewDecoder(res.Body).Decode(&data))
+	res.Body.Close()
+	require.Len(data.Tombstones, 1)
+	assert.Equal(id, data.Tombstones[0].Deployment)
+}
+
+// ownedStateServer serves rm with auth, over a state with one manifest, for
+// repo "gh", owned by sam, whose token is s3cret. Mallory's token is hunter2.
+// The put it returns PUTs body to path with a token, and returns the status.
+func ownedStateServer(t *testing.T, rm RouteMap) (*sous.State, func(token, path string, body interface{}) int, func()) {
+	require := require.New(t)
+
+	path := writeTokens(t, "s3cret sam\nhunter2 mallory\n")
+	ta, err := NewTokenAuthenticator(path)
+	require.NoError(err)
+
+	state := sous.NewState()
+	state.Manifests.Add(&sous.Manifest{
+		Source: sous.SourceLocation{Repo: "gh"},
+		Owners: []string{"sam"},
+		Kind:   sous.ManifestKindService,
+	})
+	writer := graph.LocalStateWriter{StateWriter: sous.DummyStateManager{State: state}}
+	gf := func() Injector {
+		g := psyringe.New(sous.SilentLogSet)
+		g.Add(state, writer)
+		return g
+	}
+	ts := httptest.NewServer(rm.BuildAuthRouter(gf, &Auth{Authenticator: ta}))
+
+	put := func(token, path string, body interface{}) int {
+		res, err := http.Get(ts.URL + path)
+		require.NoError(err)
+		res.Body.Close()
+		buf := &bytes.Buffer{}
+		require.NoError(json.NewEncoder(buf).Encode(body))
+		rq, err := http.NewRequest("PUT", ts.URL+path, buf)
+		require.NoError(err)
+		rq.Header.Set("If-Match", res.Header.Get("Etag"))
+		rq.Header.Set("Authorization", "Bearer "+token)
+		res, err = http.DefaultClient.Do(rq)
+		require.NoError(err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+	return state, put, func() {
+		ts.Close()
+		os.Remove(path)
+	}
+}
+
+func TestTombstonesPutChecksManifestOwners(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	state, put, done := ownedStateServer(t, RouteMap{{"tombstones", "/tombstones", &TombstonesResource{}}})
+	defer done()
+
+	sams := sous.DeployID{ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "gh"}}, Cluster: "grace"}
+	mallorys := sous.DeployID{ManifestID: sous.ManifestID{Source: sous.SourceLocation{Repo: "other"}}, Cluster: "grace"}
+	since := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.Equal(403, put("hunter2", "/tombstones", tombstonesWrapper{Tombstones: sous.Tombstones{{Deployment: sams, Since: since}}}))
+	assert.Len(state.Tombstones, 0)
+	assert.Equal(200, put("s3cret", "/tombstones", tombstonesWrapper{Tombstones: sous.Tombstones{{Deployment: sams, Since: since}}}))
+	require.Len(state.Tombstones, 1)
+
+	// Keeping sam's tombstone as it is while adding one of their own is fine
+	// for mallory, but removing or changing sam's is not.
+	assert.Equal(200, put("hunter2", "/tombstones", tombstonesWrapper{Tombstones: sous.Tombstones{
+		{Deployment: sams, Since: since},
+		{Deployment: mallorys, Since: since},
+	}}))
+	assert.Len(state.Tombstones, 2)
+	assert.Equal(403, put("hunter2", "/tombstones", tombstonesWrapper{Tombstones: sous.Tombstones{{Deployment: mallorys, Since: since}}}))
+	assert.Equal(403, put("hunter2", "/tombstones", tombstonesWrapper{Tombstones: sous.Tombstones{
+		{Deployment: sams, Since: since.Add(time.Hour)},
+		{Deployment: mallorys, Since: since},
+	}}))
+	assert.Len(state.Tombstones, 2)
+}
@@ -45,10 +45,12 @@ type (
 	Deleteable interface {
 		Delete() Exchanger
 	}
+
+	// Postable tags ResourceFamilies that respond to POST
+	Postable interface {
+		Post() Exchanger
+	}
 	/*
-		Postable interface {
-			Post() Exchanger
-		}
 		// also consider Headable or Patchable
 		// which maybe should be named "SpecializedHead" or something
 		// Note that Patchable and SpecialPatch should be separate
@@ -62,12 +64,19 @@ type (
 
 // BuildRouter builds a returns an http.Handler based on some constant configuration
 func (rm *RouteMap) BuildRouter(grf func() Injector) http.Handler {
+	return rm.BuildAuthRouter(grf, nil)
+}
+
+// BuildAuthRouter is like BuildRouter, but the handler authenticates callers
+// with auth. If auth is nil, callers aren't authenticated.
+func (rm *RouteMap) BuildAuthRouter(grf func() Injector, auth *Auth) http.Handler {
 	r := httprouter.New()
 	ph := &StatusHandler{}
 	mh := &MetaHandler{
 		graphFac:      grf,
 		router:        r,
 		statusHandler: ph,
+		auth:          auth,
 	}
 	mh.InstallPanicHandler()
 
@@ -75,16 +84,20 @@ func (rm *RouteMap) BuildRouter(grf func() Injector) http.Handler {
 		get, canGet := e.Resource.(Getable)
 		put, canPut := e.Resource.(Putable)
 		del, canDel := e.Resource.(Deleteable)
+		post, canPost := e.Resource.(Postable)
 
 		if canGet {
-			r.Handle("GET", e.Path, mh.GetHandling(get.Get))
-			r.Handle("HEAD", e.Path, mh.HeadHandling(get.Get))
+			r.Handle("GET", e.Path, mh.CountedHandling(e.Name, mh.GetHandling(get.Get)))
+			r.Handle("HEAD", e.Path, mh.CountedHandling(e.Name, mh.HeadHandling(get.Get)))
 		}
 		if canPut {
-			r.Handle("PUT", e.Path, mh.PutHandling(put.Put))
+			r.Handle("PUT", e.Path, mh.CountedHandling(e.Name, mh.PutHandling(put.Put)))
 		}
 		if canDel {
-			r.Handle("DELETE", e.Path, mh.DeleteHandling(del.Delete))
+			r.Handle("DELETE", e.Path, mh.CountedHandling(e.Name, mh.DeleteHandling(del.Delete)))
+		}
+		if canPost {
+			r.Handle("POST", e.Path, mh.CountedHandling(e.Name, mh.PostHandling(post.Post)))
 		}
 	}
 
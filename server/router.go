@@ -11,8 +11,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/opentable/sous/util/metrics"
 )
 
 type (
@@ -21,6 +23,8 @@ type (
 		router        *httprouter.Router
 		graphFac      GraphFactory //XXX This is a workaround for a bug in psyringe.Clone()
 		statusHandler *StatusHandler
+		// auth is nil if callers aren't authenticated.
+		auth *Auth
 	}
 
 	// ResponseWriter wraps the the http.ResponseWriter interface
@@ -36,8 +40,47 @@ type (
 	}
 	// A GraphFactory builds a SousGraph
 	GraphFactory func() Injector
+
+	// A RawBody is written to the response as it is, rather than encoded as
+	// JSON.
+	RawBody interface {
+		io.WriterTo
+		ContentType() string
+	}
+
+	// statusRecorder remembers the status written to a response.
+	statusRecorder struct {
+		http.ResponseWriter
+		status int
+	}
 )
 
+var httpResponses = metrics.Default.NewCounter("sous_http_responses_total",
+	"Responses from sous server, by route, method and status code.",
+	"route", "method", "code")
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// CountedHandling counts the responses of handle in the metrics, by route
+// name, method and status code.
+func (mh *MetaHandler) CountedHandling(route string, handle httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		sr := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				// The router's panic handler responds with a 500.
+				httpResponses.Inc(route, r.Method, strconv.Itoa(http.StatusInternalServerError))
+				panic(recovered)
+			}
+			httpResponses.Inc(route, r.Method, strconv.Itoa(sr.status))
+		}()
+		handle(sr, r, p)
+	}
+}
+
 // Seriously considering "BodyInBodyOut" "BodyInEmptyOut" "EmptyInBodyOut" "EmptyInEmptyOut"
 // because that covers pretty much every case for the requests themselves, and
 // the exchanger is really the crucial part of the transform
@@ -45,8 +88,7 @@ type (
 // GetHandling handles Get requests
 func (mh *MetaHandler) GetHandling(factory ExchangeFactory) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
-		h := mh.injectedHandler(factory, w, r, p)
-		data, status := h.Exchange()
+		data, status := mh.exchange(factory, w, r, p)
 		mh.renderData(status, w, r, data)
 	}
 }
@@ -54,17 +96,27 @@ func (mh *MetaHandler) GetHandling(factory ExchangeFactory) httprouter.Handle {
 // DeleteHandling handles Delete requests
 func (mh *MetaHandler) DeleteHandling(factory ExchangeFactory) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
-		h := mh.injectedHandler(factory, w, r, p)
-		_, status := h.Exchange()
+		if status := mh.checkPreconditions(r); status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		_, status := mh.exchange(factory, w, r, p)
 		mh.renderData(status, w, r, nil)
 	}
 }
 
+// PostHandling handles POST requests
+func (mh *MetaHandler) PostHandling(factory ExchangeFactory) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		data, status := mh.exchange(factory, w, r, p)
+		mh.renderData(status, w, r, data)
+	}
+}
+
 // HeadHandling handles Head requests
 func (mh *MetaHandler) HeadHandling(factory ExchangeFactory) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
-		h := mh.injectedHandler(factory, w, r, p)
-		_, status := h.Exchange()
+		_, status := mh.exchange(factory, w, r, p)
 		mh.writeHeaders(status, w, r, nil)
 	}
 }
@@ -72,29 +124,45 @@ func (mh *MetaHandler) HeadHandling(factory ExchangeFactory) httprouter.Handle {
 // PutHandling handles PUT requests
 func (mh *MetaHandler) PutHandling(factory ExchangeFactory) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
-		if r.Header.Get("If-Match") == "" && r.Header.Get("If-None-Match") == "" {
-			w.WriteHeader(http.StatusPreconditionRequired)
+		if status := mh.checkPreconditions(r); status != http.StatusOK {
+			w.WriteHeader(status)
 			return
 		}
+		data, status := mh.exchange(factory, w, r, p)
+		mh.renderData(status, w, r, data)
+	}
+}
 
-		gr := copyRequest(r)
-		gr.Method = "GET"
-		grez := mh.synthResponse(gr)
+// checkPreconditions checks the If-Match and If-None-Match headers of a
+// request which changes a resource against a synthesised GET of it. One of
+// them is required, so that clients can't clobber changes they haven't seen.
+func (mh *MetaHandler) checkPreconditions(r *http.Request) int {
+	if r.Header.Get("If-Match") == "" && r.Header.Get("If-None-Match") == "" {
+		return http.StatusPreconditionRequired
+	}
 
-		if r.Header.Get("If-None-Match") == "*" && grez.StatusCode != 404 {
-			w.WriteHeader(http.StatusPreconditionFailed)
-			return
-		}
-		if etag := r.Header.Get("If-Match"); etag != "" {
-			if grez.Header.Get("Etag") != etag {
-				w.WriteHeader(http.StatusPreconditionFailed)
-				return
-			}
+	gr := copyRequest(r)
+	gr.Method = "GET"
+	grez := mh.synthResponse(gr)
+	switch grez.StatusCode {
+	default:
+		// e.g. the caller couldn't be authenticated
+		return grez.StatusCode
+	case http.StatusNotModified:
+		// the resource matches If-None-Match
+		return http.StatusPreconditionFailed
+	case http.StatusOK, http.StatusNotFound:
+	}
+
+	if r.Header.Get("If-None-Match") == "*" && grez.StatusCode != 404 {
+		return http.StatusPreconditionFailed
+	}
+	if etag := r.Header.Get("If-Match"); etag != "" {
+		if grez.Header.Get("Etag") != etag {
+			return http.StatusPreconditionFailed
 		}
-		h := mh.injectedHandler(factory, w, r, p)
-		data, status := h.Exchange()
-		mh.renderData(status, w, r, data)
 	}
+	return http.StatusOK
 }
 
 // InstallPanicHandler installs an panic handler into the router
@@ -114,12 +182,33 @@ func (mh *MetaHandler) ExchangeGraph(w http.ResponseWriter, r *http.Request, p h
 	return g
 }
 
-func (mh *MetaHandler) injectedHandler(factory ExchangeFactory, w http.ResponseWriter, r *http.Request, p httprouter.Params) Exchanger {
-	h := factory()
+// exchange authenticates the caller, and then makes the exchange with a
+// handler built by factory, if the handler authorizes the caller.
+func (mh *MetaHandler) exchange(factory ExchangeFactory, w http.ResponseWriter, r *http.Request, p httprouter.Params) (interface{}, int) {
+	caller := &Caller{Auth: mh.auth}
+	if mh.auth != nil {
+		id, err := mh.auth.Authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			return &ClientError{Message: "Authentication failed: " + err.Error()}, http.StatusUnauthorized
+		}
+		caller.Identity = id
+	}
 
-	mh.ExchangeGraph(w, r, p).Inject(h)
+	h := factory()
+	g := mh.ExchangeGraph(w, r, p)
+	g.Add(caller)
+	g.Inject(h)
 
-	return h
+	if a, is := h.(Authorizer); is {
+		if ce, status := a.Authorize(); ce != nil {
+			if status == http.StatusUnauthorized {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			return ce, status
+		}
+	}
+	return h.Exchange()
 }
 
 func (mh *MetaHandler) writeHeaders(status int, w http.ResponseWriter, r *http.Request, data interface{}) {
@@ -127,6 +216,14 @@ func (mh *MetaHandler) writeHeaders(status int, w http.ResponseWriter, r *http.R
 }
 
 func (mh *MetaHandler) renderData(status int, w http.ResponseWriter, r *http.Request, data interface{}) {
+	if ce, is := data.(*ClientError); is && status >= 400 {
+		mh.renderClientError(status, w, r, ce)
+		return
+	}
+	if rb, is := data.(RawBody); is && status < 300 {
+		mh.renderRawBody(status, w, r, rb)
+		return
+	}
 	if data == nil || status >= 300 {
 		mh.writeHeaders(status, w, r, data)
 		return
@@ -137,13 +234,41 @@ func (mh *MetaHandler) renderData(status int, w http.ResponseWriter, r *http.Req
 	// xxx conneg
 	e := json.NewEncoder(io.MultiWriter(buf, digest))
 	e.Encode(data)
+	etag := base64.URLEncoding.EncodeToString(digest.Sum(nil))
+	w.Header().Add("Etag", etag)
+	if r.Method == "GET" && r.Header.Get("If-None-Match") == etag {
+		// The caller already has this representation.
+		mh.writeHeaders(http.StatusNotModified, w, r, nil)
+		return
+	}
 	w.Header().Add("Content-Type", "application/json")
 	w.Header().Add("Content-Length", fmt.Sprintf("%d", buf.Len()))
-	w.Header().Add("Etag", base64.URLEncoding.EncodeToString(digest.Sum(nil)))
 	mh.writeHeaders(status, w, r, data)
 	buf.WriteTo(w)
 }
 
+func (mh *MetaHandler) renderClientError(status int, w http.ResponseWriter, r *http.Request, ce *ClientError) {
+	buf := &bytes.Buffer{}
+	json.NewEncoder(buf).Encode(ce)
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Add("Content-Length", fmt.Sprintf("%d", buf.Len()))
+	mh.writeHeaders(status, w, r, ce)
+	buf.WriteTo(w)
+}
+
+func (mh *MetaHandler) renderRawBody(status int, w http.ResponseWriter, r *http.Request, rb RawBody) {
+	buf := &bytes.Buffer{}
+	if _, err := rb.WriteTo(buf); err != nil {
+		mh.writeHeaders(http.StatusInternalServerError, w, r, err)
+		return
+	}
+	w.Header().Add("Content-Type", rb.ContentType())
+	w.Header().Add("Content-Length", fmt.Sprintf("%d", buf.Len()))
+	// The body isn't logged: it's no more use in the log than in the response.
+	mh.writeHeaders(status, w, r, nil)
+	buf.WriteTo(w)
+}
+
 func emptyBody() io.ReadCloser {
 	return ioutil.NopCloser(&bytes.Buffer{})
 }
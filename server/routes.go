@@ -5,7 +5,16 @@ var (
 	SousRouteMap = RouteMap{
 		{"gdm", "/gdm", &GDMResource{}},
 		{"defs", "/defs", &StateDefResource{}},
+		{"cluster", "/defs/cluster", &ClusterDefResource{}},
 		{"manifest", "/manifest", &ManifestResource{}},
+		{"manifest-history", "/manifest/history", &ManifestHistoryResource{}},
+		{"deployment", "/deployment", &DeploymentResource{}},
+		{"tombstones", "/tombstones", &TombstonesResource{}},
+		{"rollbacks", "/rollbacks", &RollbacksResource{}},
 		{"artifact", "/artifact", &ArtifactResource{}},
+		{"history", "/history", &HistoryResource{}},
+		{"metrics", "/metrics", &MetricsResource{}},
+		{"status", "/status", &StatusResource{}},
+		{"resolve", "/resolve", &ResolveResource{}},
 	}
 )
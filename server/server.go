@@ -1,28 +1,107 @@
 package server
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/opentable/sous/config"
 	"github.com/opentable/sous/graph"
+	"github.com/opentable/sous/lib"
+	"github.com/pkg/errors"
 )
 
 // New creates a Sous HTTP server.
 func New(laddr string, gf GraphFactory) *http.Server {
+	return NewAuth(laddr, gf, nil)
+}
+
+// NewAuth creates a Sous HTTP server which authenticates callers with auth.
+// If auth is nil, it doesn't, and anybody may change anything.
+func NewAuth(laddr string, gf GraphFactory, auth *Auth) *http.Server {
 	return &http.Server{
 		Addr:    laddr,
-		Handler: SousRouteMap.BuildRouter(gf),
+		Handler: SousRouteMap.BuildAuthRouter(gf, auth),
 	}
 }
 
-// RunServer starts a server up.
-func RunServer(v *config.Verbosity, laddr string) error {
+// RunServer starts a server up. If ar is not nil, the server starts its
+// resolve cycle, and reports on and triggers its resolves. The server
+// authenticates callers, and serves HTTPS, as configured by cfg.
+func RunServer(v *config.Verbosity, laddr string, ar *sous.AutoResolver, cfg *config.Config) error {
+	auth, err := NewAuthFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	// Every request's graph shares the resolve history, which is closed
+	// when the server stops.
+	defer graph.CloseResolveHistories()
+	if ar != nil {
+		ar.Kickoff()
+	}
 	gf := func() Injector {
 		g := graph.BuildGraph(os.Stdout, os.Stdout)
 		g.Add(v)
+		if ar != nil {
+			g.Add(&ResolveLoop{ar})
+		}
 		return g
 	}
-	s := New(laddr, gf)
-	return s.ListenAndServe()
+	s := NewAuth(laddr, gf, auth)
+	if cfg.ServerCert == "" {
+		return s.ListenAndServe()
+	}
+	if cfg.ClientCAs != "" {
+		pool, err := loadCertPool(cfg.ClientCAs)
+		if err != nil {
+			return err
+		}
+		s.TLSConfig = &tls.Config{
+			ClientCAs:  pool,
+			ClientAuth: tls.VerifyClientCertIfGiven,
+		}
+	}
+	return s.ListenAndServeTLS(cfg.ServerCert, cfg.ServerKey)
+}
+
+// NewAuthFromConfig returns the Auth described by cfg: callers are
+// authenticated by bearer token if cfg.AuthTokensFile is set, and by client
+// certificate if cfg.ClientCAs is set. If neither is set, it returns nil.
+func NewAuthFromConfig(cfg *config.Config) (*Auth, error) {
+	var as Authenticators
+	if cfg.AuthTokensFile != "" {
+		ta, err := NewTokenAuthenticator(cfg.AuthTokensFile)
+		if err != nil {
+			return nil, err
+		}
+		as = append(as, ta)
+	}
+	if cfg.ClientCAs != "" {
+		as = append(as, TLSAuthenticator{})
+	}
+	if len(as) == 0 {
+		return nil, nil
+	}
+	auth := &Auth{Authenticator: as}
+	for _, admin := range strings.Split(cfg.Admins, ",") {
+		if admin = strings.TrimSpace(admin); admin != "" {
+			auth.Admins = append(auth.Admins, admin)
+		}
+	}
+	return auth, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading client CAs")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
 }
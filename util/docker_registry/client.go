@@ -18,6 +18,7 @@ import (
 	"github.com/docker/distribution/registry/api/v2"
 	"github.com/docker/distribution/registry/client"
 	"github.com/docker/distribution/registry/client/transport"
+	"github.com/opentable/sous/util/metrics"
 	"golang.org/x/net/context"
 )
 
@@ -96,6 +97,10 @@ func (rs *Registries) DeleteRegistry(n string) error {
 	return nil
 }
 
+var requestDurations = metrics.Default.NewHistogram("sous_registry_request_duration_seconds",
+	"How long requests to Docker registries took, by HTTP method and response code.",
+	nil, "method", "code")
+
 // NewClient builds a new client
 func NewClient() Client {
 	return &liveClient{
@@ -209,7 +214,7 @@ func (c *liveClient) registryForHostname(regHost string) (*registry, error) {
 	if reg := c.GetRegistry(url); reg != nil {
 		return reg, nil
 	}
-	reg, err := newRegistry(url, c.xport)
+	reg, err := newRegistry(url, metrics.InstrumentTransport(c.xport, requestDurations))
 	if err != nil {
 		return nil, err
 	}
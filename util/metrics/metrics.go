@@ -0,0 +1,263 @@
+// Package metrics collects counters, gauges and histograms, and writes them
+// in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// A Registry holds metrics, and writes them all out together.
+	Registry struct {
+		sync.Mutex
+		families map[string]*family
+	}
+
+	// A Counter is a metric which only goes up, e.g. a count of errors.
+	Counter struct{ *family }
+
+	// A Gauge is a metric which may go up and down, e.g. a number of
+	// deployments.
+	Gauge struct{ *family }
+
+	// A Histogram counts observations, e.g. request durations, in buckets.
+	Histogram struct{ *family }
+
+	// family is a metric and the series it has for each set of label values.
+	family struct {
+		sync.Mutex
+		name, help, kind string
+		labels           []string
+		buckets          []float64
+		series           map[string]*series
+	}
+
+	series struct {
+		labelValues []string
+		value       float64
+		counts      []uint64
+		count       uint64
+	}
+)
+
+// DefaultBuckets are histogram buckets suitable for durations in seconds,
+// from network calls up to whole resolve cycles.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// Default is the registry that sous server exposes.
+var Default = NewRegistry()
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{families: map[string]*family{}}
+}
+
+// NewCounter adds a counter to the registry. Each series is picked by values
+// for labels.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{r.add(name, help, "counter", nil, labels)}
+}
+
+// NewGauge adds a gauge to the registry.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{r.add(name, help, "gauge", nil, labels)}
+}
+
+// NewHistogram adds a histogram to the registry. If buckets is nil,
+// DefaultBuckets is used.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{r.add(name, help, "histogram", buckets, labels)}
+}
+
+func (r *Registry) add(name, help, kind string, buckets []float64, labels []string) *family {
+	r.Lock()
+	defer r.Unlock()
+	if _, exists := r.families[name]; exists {
+		panic(fmt.Sprintf("metric %q registered twice", name))
+	}
+	f := &family{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  labels,
+		buckets: buckets,
+		series:  map[string]*series{},
+	}
+	r.families[name] = f
+	return f
+}
+
+// Inc adds 1 to the series for labelValues.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative, to the series for labelValues.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic(fmt.Sprintf("counter %q can't be decreased", c.name))
+	}
+	c.update(labelValues, func(s *series) { s.value += v })
+}
+
+// Set sets the series for labelValues to v.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.update(labelValues, func(s *series) { s.value = v })
+}
+
+// Observe counts v in the series for labelValues.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.update(labelValues, func(s *series) {
+		if s.counts == nil {
+			s.counts = make([]uint64, len(h.buckets))
+		}
+		for i, upper := range h.buckets {
+			if v <= upper {
+				s.counts[i]++
+			}
+		}
+		s.count++
+		s.value += v
+	})
+}
+
+// ObserveSince observes the number of seconds since start.
+func (h *Histogram) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+func (f *family) update(labelValues []string, change func(*series)) {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metric %q has labels %v, but was given values %v", f.name, f.labels, labelValues))
+	}
+	key := strings.Join(labelValues, "\xff")
+	f.Lock()
+	defer f.Unlock()
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string{}, labelValues...)}
+		f.series[key] = s
+	}
+	change(s)
+}
+
+// WriteTo writes every metric in the registry to w in the Prometheus text
+// exposition format, ordered by name and then by label values.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.Lock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	families := make([]*family, len(names))
+	for i, name := range names {
+		families[i] = r.families[name]
+	}
+	r.Unlock()
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, f := range families {
+		f.writeTo(bw)
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+func (f *family) writeTo(w *bufio.Writer) {
+	f.Lock()
+	defer f.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := f.series[key]
+		if f.kind != "histogram" {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, f.labelString(s.labelValues, "", 0), formatFloat(s.value))
+			continue
+		}
+		for i, upper := range f.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labelString(s.labelValues, "le", upper), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labelString(s.labelValues, "le", math.Inf(1)), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, f.labelString(s.labelValues, "", 0), formatFloat(s.value))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, f.labelString(s.labelValues, "", 0), s.count)
+	}
+}
+
+// labelString renders labels with values as {a="x",b="y"}. If le is not
+// empty, it is added as a final label with the value upper.
+func (f *family) labelString(values []string, le string, upper float64) string {
+	pairs := make([]string, 0, len(values)+1)
+	for i, l := range f.labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", l, escapeLabel(values[i])))
+	}
+	if le != "" {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", le, formatFloat(upper)))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+// escapeLabel prepares a label value to be quoted with %q, which escapes
+// backslashes, quotes and newlines itself, but also anything unprintable,
+// which Prometheus wouldn't understand.
+func escapeLabel(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r != '\n' && !strconv.IsPrint(r) {
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
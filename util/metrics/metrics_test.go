@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nyarly/testify/assert"
+	"github.com/nyarly/testify/require"
+)
+
+func TestWriteTo(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	r := NewRegistry()
+	c := r.NewCounter("errors_total", "Errors,\nby kind.", "kind")
+	g := r.NewGauge("things", "Things.")
+	h := r.NewHistogram("duration_seconds", "Durations.", []float64{1, 0.5}, "outcome")
+
+	c.Inc("bad")
+	c.Add(2, `"worse"`)
+	c.Inc("bad")
+	g.Set(4.5)
+	h.Observe(0.25, "success")
+	h.Observe(0.75, "success")
+	h.Observe(2, "success")
+
+	buf := &bytes.Buffer{}
+	n, err := r.WriteTo(buf)
+	require.NoError(err)
+	assert.EqualValues(buf.Len(), n)
+	assert.Equal(`# HELP duration_seconds Durations.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{outcome="success",le="0.5"} 1
+duration_seconds_bucket{outcome="success",le="1"} 2
+duration_seconds_bucket{outcome="success",le="+Inf"} 3
+duration_seconds_sum{outcome="success"} 3
+duration_seconds_count{outcome="success"} 3
+# HELP errors_total Errors,\nby kind.
+# TYPE errors_total counter
+errors_total{kind="\"worse\""} 2
+errors_total{kind="bad"} 2
+# HELP things Things.
+# TYPE things gauge
+things 4.5
+`, buf.String())
+}
+
+func TestWrongLabels(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("errors_total", "Errors.", "kind")
+	assert.Panics(t, func() { c.Inc() })
+	assert.Panics(t, func() { r.NewGauge("errors_total", "Again.") })
+}
+
+func TestInstrumentTransport(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer ts.Close()
+
+	r := NewRegistry()
+	h := r.NewHistogram("request_duration_seconds", "Requests.", nil, "method", "code")
+	cl := &http.Client{Transport: InstrumentTransport(nil, h)}
+
+	res, err := cl.Get(ts.URL)
+	require.NoError(err)
+	res.Body.Close()
+	_, err = cl.Get("http://127.0.0.1:0/")
+	require.Error(err)
+
+	buf := &bytes.Buffer{}
+	r.WriteTo(buf)
+	assert.Contains(buf.String(), `request_duration_seconds_count{method="GET",code="418"} 1`)
+	assert.Contains(buf.String(), `request_duration_seconds_count{method="GET",code="error"} 1`)
+}
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type instrumentedTransport struct {
+	next      http.RoundTripper
+	durations *Histogram
+}
+
+// InstrumentTransport returns an http.RoundTripper which makes requests with
+// next, and observes how long each takes in durations. durations must have
+// the labels "method" and "code": code is the response's status code, or
+// "error" if there was no response.
+func InstrumentTransport(next http.RoundTripper, durations *Histogram) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &instrumentedTransport{next: next, durations: durations}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (it *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := it.next.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(res.StatusCode)
+	}
+	it.durations.ObserveSince(start, req.Method, code)
+	return res, err
+}